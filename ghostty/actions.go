@@ -0,0 +1,93 @@
+package ghostty
+
+import "strings"
+
+// keybind actions and whether they take a parameter after a colon, eg
+// goto_tab:1. Parameters are required unless the action is listed in
+// optionalParameter.
+var actions = map[string]bool{
+	"ignore":                     false,
+	"unbind":                     false,
+	"csi":                        true,
+	"esc":                        true,
+	"text":                       true,
+	"cursor_key":                 true,
+	"reset":                      false,
+	"copy_to_clipboard":          false,
+	"paste_from_clipboard":       false,
+	"paste_from_selection":       false,
+	"copy_url_to_clipboard":      false,
+	"copy_title_to_clipboard":    false,
+	"increase_font_size":         true,
+	"decrease_font_size":         true,
+	"reset_font_size":            false,
+	"set_font_size":              true,
+	"clear_screen":               false,
+	"select_all":                 false,
+	"scroll_to_top":              false,
+	"scroll_to_bottom":           false,
+	"scroll_to_selection":        false,
+	"scroll_page_up":             false,
+	"scroll_page_down":           false,
+	"scroll_page_fractional":     true,
+	"scroll_page_lines":          true,
+	"adjust_selection":           true,
+	"jump_to_prompt":             true,
+	"write_scrollback_file":      true,
+	"write_screen_file":          true,
+	"write_selection_file":       true,
+	"new_window":                 false,
+	"new_tab":                    false,
+	"previous_tab":               false,
+	"next_tab":                   false,
+	"last_tab":                   false,
+	"goto_tab":                   true,
+	"move_tab":                   true,
+	"toggle_tab_overview":        false,
+	"prompt_surface_title":       false,
+	"new_split":                  true,
+	"goto_split":                 true,
+	"toggle_split_zoom":          false,
+	"resize_split":               true,
+	"equalize_splits":            false,
+	"inspector":                  true,
+	"show_gtk_inspector":         false,
+	"open_config":                false,
+	"reload_config":              false,
+	"close_surface":              false,
+	"close_tab":                  false,
+	"close_window":               false,
+	"close_all_windows":          false,
+	"toggle_maximize":            false,
+	"toggle_fullscreen":          false,
+	"toggle_window_decorations":  false,
+	"toggle_window_float_on_top": false,
+	"toggle_secure_input":        false,
+	"toggle_command_palette":     false,
+	"toggle_quick_terminal":      false,
+	"toggle_visibility":          false,
+	"toggle_background_opacity":  false,
+	"check_for_updates":          false,
+	"undo":                       false,
+	"redo":                       false,
+	"quit":                       false,
+	"crash":                      true,
+}
+
+// actions whose parameter may be left out
+var optionalParameter = map[string]bool{
+	"increase_font_size": true,
+	"decrease_font_size": true,
+	"jump_to_prompt":     true,
+}
+
+// IsAction reports whether name is a keybind action ghostty knows
+func IsAction(name string) bool {
+	_, ok := actions[name]
+	return ok
+}
+
+// splitAction separates an action from its parameter, eg goto_tab:1
+func splitAction(action string) (string, string, bool) {
+	return strings.Cut(action, ":")
+}
@@ -0,0 +1,119 @@
+package ghostty
+
+import (
+	_ "embed"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+//go:embed default_keybinds.txt
+var defaultKeybindsSource string
+
+// Keybind is one trigger=action binding
+type Keybind struct {
+	Trigger string
+	Action  string
+}
+
+var defaultKeybinds = parseDefaultKeybinds(defaultKeybindsSource)
+
+// parseDefaultKeybinds reads the embedded defaults by platform
+func parseDefaultKeybinds(source string) map[string][]Keybind {
+	parsed := make(map[string][]Keybind)
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		platform, binding, _ := strings.Cut(line, " ")
+		trigger, action, ok := SplitKeybind(binding)
+		if !ok {
+			panic(fmt.Sprintf("ghostty default keybinds line %d: expected trigger=action", i+1))
+		}
+		parsed[platform] = append(parsed[platform], Keybind{trigger, action})
+	}
+	return parsed
+}
+
+// Platform is the ghostty platform name for the running system
+func Platform() string {
+	if runtime.GOOS == "darwin" {
+		return "macos"
+	}
+	return "linux"
+}
+
+// DefaultKeybinds returns ghostty's default bindings on platform
+func DefaultKeybinds(platform string) []Keybind {
+	return append(slices.Clone(defaultKeybinds["all"]), defaultKeybinds[platform]...)
+}
+
+// modifier spellings ghostty accepts
+var modifierAliases = map[string]string{
+	"shift":   "shift",
+	"ctrl":    "ctrl",
+	"control": "ctrl",
+	"alt":     "alt",
+	"opt":     "alt",
+	"option":  "alt",
+	"super":   "super",
+	"cmd":     "super",
+	"command": "super",
+}
+
+// key spellings that name the same key, mapped to the form used in
+// default_keybinds.txt
+var keyAliases = map[string]string{
+	"zero": "0", "one": "1", "two": "2", "three": "3", "four": "4",
+	"five": "5", "six": "6", "seven": "7", "eight": "8", "nine": "9",
+	"=": "equal", "+": "plus", "-": "minus", ",": "comma", ".": "period",
+	"/": "slash", "\\": "backslash", ";": "semicolon", "'": "apostrophe",
+	"`": "grave_accent", "[": "left_bracket", "]": "right_bracket",
+	"backquote": "grave_accent", "quote": "apostrophe",
+	"bracket_left": "left_bracket", "bracket_right": "right_bracket",
+	"arrow_up": "up", "arrow_down": "down", "arrow_left": "left", "arrow_right": "right",
+	"return": "enter", "esc": "escape",
+}
+
+// NormalizeTrigger writes a trigger in one canonical form so equal triggers
+// compare equal: prefixes dropped, modifiers sorted, key aliases resolved
+func NormalizeTrigger(trigger string) string {
+	for {
+		prefix, rest, ok := strings.Cut(trigger, ":")
+		if !ok || !slices.Contains(keybindPrefixes, prefix) {
+			break
+		}
+		trigger = rest
+	}
+
+	steps := strings.Split(trigger, ">")
+	for i, step := range steps {
+		parts := strings.Split(strings.ToLower(step), "+")
+		if strings.HasSuffix(step, "++") {
+			parts = append(parts[:len(parts)-2], "+")
+		}
+
+		key := parts[len(parts)-1]
+		key = strings.TrimPrefix(key, "key_")
+		key = strings.TrimPrefix(key, "digit_")
+		if alias, ok := keyAliases[key]; ok {
+			key = alias
+		}
+
+		var modifiers []string
+		for _, modifier := range parts[:len(parts)-1] {
+			if alias, ok := modifierAliases[modifier]; ok {
+				modifier = alias
+			}
+			if !slices.Contains(modifiers, modifier) {
+				modifiers = append(modifiers, modifier)
+			}
+		}
+		slices.Sort(modifiers)
+		steps[i] = strings.Join(append(modifiers, key), "+")
+	}
+	return strings.Join(steps, ">")
+}
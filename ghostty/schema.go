@@ -0,0 +1,135 @@
+// Package ghostty describes the Ghostty configuration format: the keys it
+// accepts, the values they take and the keybind actions it knows.
+package ghostty
+
+import (
+	_ "embed"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+//go:embed schema.txt
+var schemaSource string
+
+// Option is one key of the ghostty config
+type Option struct {
+	Key  string
+	Type string
+
+	// repeatable keys collect every value instead of the last one winning
+	Repeatable bool
+
+	// macos or linux when the key only has an effect on that platform
+	Platform string
+
+	// allowed values for enum and flags keys
+	Values []string
+}
+
+var options = parseSchema(schemaSource)
+
+// parseSchema reads the embedded key catalog, the format is described at the
+// top of schema.txt
+func parseSchema(source string) map[string]Option {
+	parsed := make(map[string]Option)
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			panic(fmt.Sprintf("ghostty schema line %d: expected a key and a type", i+1))
+		}
+		option := Option{Key: fields[0], Type: fields[1]}
+		for _, field := range fields[2:] {
+			switch {
+			case field == "repeatable":
+				option.Repeatable = true
+			case field == "macos", field == "linux":
+				option.Platform = field
+			case strings.HasPrefix(field, "values="):
+				option.Values = strings.Split(strings.TrimPrefix(field, "values="), ",")
+			default:
+				panic(fmt.Sprintf("ghostty schema line %d: unknown attribute %q", i+1, field))
+			}
+		}
+		parsed[option.Key] = option
+	}
+	return parsed
+}
+
+// Lookup returns the option for key
+func Lookup(key string) (Option, bool) {
+	option, ok := options[key]
+	return option, ok
+}
+
+// Keys returns every known key, sorted
+func Keys() []string {
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// IsRepeatable reports whether key may be given more than once
+func IsRepeatable(key string) bool {
+	return options[key].Repeatable
+}
+
+// AppliesHere reports whether the option has an effect on this platform
+func (o Option) AppliesHere() bool {
+	switch o.Platform {
+	case "macos":
+		return runtime.GOOS == "darwin"
+	case "linux":
+		return runtime.GOOS != "darwin" && runtime.GOOS != "windows"
+	}
+	return true
+}
+
+// Suggest returns the known key closest to an unknown one, or "" when
+// nothing is close enough to be a likely typo
+func Suggest(key string) string {
+	// kitty and alacritty style names, eg font_size
+	normalised := strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+	if _, ok := options[normalised]; ok {
+		return normalised
+	}
+
+	best, bestDistance := "", len(normalised)/3+1
+	for _, candidate := range Keys() {
+		distance := levenshtein(normalised, candidate)
+		if distance < bestDistance {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// levenshtein is the edit distance between two strings
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
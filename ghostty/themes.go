@@ -0,0 +1,62 @@
+package ghostty
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// themeDirs are the directories ghostty installs its themes to
+func themeDirs() []string {
+	var dirs []string
+	if resources := os.Getenv("GHOSTTY_RESOURCES_DIR"); resources != "" {
+		dirs = append(dirs, filepath.Join(resources, "themes"))
+	}
+
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+	for _, dir := range filepath.SplitList(dataDirs) {
+		dirs = append(dirs, filepath.Join(dir, "ghostty", "themes"))
+	}
+	return append(dirs, "/Applications/Ghostty.app/Contents/Resources/ghostty/themes")
+}
+
+// FindTheme looks for a theme ghostty ships called name and returns
+// ghostty's name for it. Names match ignoring case, spaces, dashes and
+// underscores, so Tokyo Night finds TokyoNight.
+func FindTheme(name string) (string, bool) {
+	if name == "" {
+		return "", false
+	}
+	var similar string
+	for _, dir := range themeDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			if entry.Name() == name {
+				return name, true
+			}
+			if similar == "" && normalizeThemeName(entry.Name()) == normalizeThemeName(name) {
+				similar = entry.Name()
+			}
+		}
+	}
+	return similar, similar != ""
+}
+
+func normalizeThemeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '-', '_':
+			return -1
+		}
+		return r
+	}, strings.ToLower(name))
+}
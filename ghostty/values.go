@@ -0,0 +1,259 @@
+package ghostty
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	metricPattern    = regexp.MustCompile(`^-?\d+(\.\d+)?%?$`)
+	durationPattern  = regexp.MustCompile(`^(\d+(ns|us|µs|ms|s|m|h|d|w|y)\s*)+$`)
+	codepointPattern = regexp.MustCompile(`^(?i)U\+[0-9a-f]{1,6}(-U\+[0-9a-f]{1,6})?$`)
+	keybindPrefixes  = []string{"global", "all", "unconsumed", "performable"}
+	keybindModifiers = []string{"shift", "ctrl", "control", "alt", "opt", "option", "super", "cmd", "command"}
+)
+
+// Check validates value for the option. An empty value resets any key to its
+// default and is always accepted.
+func (o Option) Check(value string) error {
+	if value == "" {
+		return nil
+	}
+
+	switch o.Type {
+	case "bool":
+		if value != "true" && value != "false" {
+			return fmt.Errorf("expected true or false, got %q", value)
+		}
+	case "int":
+		if _, err := strconv.ParseInt(value, 10, 64); err != nil {
+			return fmt.Errorf("expected a whole number, got %q", value)
+		}
+	case "uint":
+		if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+			return fmt.Errorf("expected a positive whole number, got %q", value)
+		}
+	case "float":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("expected a number, got %q", value)
+		}
+	case "color":
+		return CheckColor(value)
+	case "terminal-color":
+		if value == "cell-foreground" || value == "cell-background" {
+			return nil
+		}
+		return CheckColor(value)
+	case "enum":
+		if !slices.Contains(o.Values, value) {
+			return fmt.Errorf("expected one of %s, got %q", strings.Join(o.Values, ", "), value)
+		}
+	case "flags":
+		return o.checkFlags(value)
+	case "palette":
+		return checkPalette(value)
+	case "keybind":
+		return CheckKeybind(value)
+	case "padding":
+		return checkPadding(value)
+	case "metric":
+		if !metricPattern.MatchString(value) {
+			return fmt.Errorf("expected a number of pixels or a percentage, got %q", value)
+		}
+	case "duration":
+		if !durationPattern.MatchString(value) {
+			return fmt.Errorf("expected a duration such as 500ms or 1s, got %q", value)
+		}
+	case "blur":
+		if value == "true" || value == "false" {
+			return nil
+		}
+		if _, err := strconv.ParseUint(value, 10, 8); err != nil {
+			return fmt.Errorf("expected true, false or a blur radius, got %q", value)
+		}
+	case "codepoint-map":
+		return checkCodepointMap(value)
+	case "theme":
+		return checkTheme(value)
+	}
+	return nil
+}
+
+// flags are a comma separated list, a no- prefix turns one off
+func (o Option) checkFlags(value string) error {
+	if value == "true" || value == "false" {
+		return nil
+	}
+	for _, flag := range strings.Split(value, ",") {
+		flag = strings.TrimPrefix(strings.TrimSpace(flag), "no-")
+		if !slices.Contains(o.Values, flag) {
+			return fmt.Errorf("unknown flag %q, expected %s", flag, strings.Join(o.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// CheckColor accepts hex colors with or without a # and color names
+func CheckColor(value string) error {
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) == 3 || len(hex) == 6 {
+		if _, err := strconv.ParseUint(hex, 16, 32); err == nil {
+			return nil
+		}
+	}
+	if isColorName(strings.ToLower(value)) {
+		return nil
+	}
+	return fmt.Errorf("expected a hex color or color name, got %q", value)
+}
+
+func isColorName(value string) bool {
+	if value == "" || value[0] < 'a' || value[0] > 'z' {
+		return false
+	}
+	for _, c := range value {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == ' ') {
+			return false
+		}
+	}
+	return true
+}
+
+// palette entries are index=color
+func checkPalette(value string) error {
+	index, color, ok := strings.Cut(value, "=")
+	if !ok {
+		return fmt.Errorf("expected index=color, got %q", value)
+	}
+	if n, err := strconv.ParseUint(strings.TrimSpace(index), 10, 8); err != nil || n > 255 {
+		return fmt.Errorf("palette index must be between 0 and 255, got %q", index)
+	}
+	return CheckColor(strings.TrimSpace(color))
+}
+
+// padding is one value for both sides or a left,right (top,bottom) pair
+func checkPadding(value string) error {
+	parts := strings.Split(value, ",")
+	if len(parts) > 2 {
+		return fmt.Errorf("expected one or two numbers, got %q", value)
+	}
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(strings.TrimSpace(part), 10, 32); err != nil {
+			return fmt.Errorf("expected one or two positive whole numbers, got %q", value)
+		}
+	}
+	return nil
+}
+
+// codepoint maps are U+XXXX or U+XXXX-U+YYYY ranges, comma separated, =font
+func checkCodepointMap(value string) error {
+	ranges, font, ok := strings.Cut(value, "=")
+	if !ok || strings.TrimSpace(font) == "" {
+		return fmt.Errorf("expected U+XXXX-U+YYYY=font family, got %q", value)
+	}
+	for _, codepoints := range strings.Split(ranges, ",") {
+		if !codepointPattern.MatchString(strings.TrimSpace(codepoints)) {
+			return fmt.Errorf("invalid codepoint range %q", codepoints)
+		}
+	}
+	return nil
+}
+
+// a theme is a name, or light:name,dark:name to follow the system
+func checkTheme(value string) error {
+	if !strings.Contains(value, ":") {
+		return nil
+	}
+	for _, part := range strings.Split(value, ",") {
+		mode, name, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok || (mode != "light" && mode != "dark") || name == "" {
+			return fmt.Errorf("expected light:name,dark:name, got %q", value)
+		}
+	}
+	return nil
+}
+
+// CheckKeybind validates a keybind value, eg ctrl+shift+t=new_tab
+func CheckKeybind(value string) error {
+	if value == "clear" {
+		return nil
+	}
+
+	trigger, action, ok := SplitKeybind(value)
+	if !ok {
+		return fmt.Errorf("expected trigger=action, got %q", value)
+	}
+	if err := checkTrigger(trigger); err != nil {
+		return err
+	}
+	return checkAction(action)
+}
+
+// SplitKeybind separates the trigger from the action. The trigger may itself
+// end in an = key, eg ctrl+==increase_font_size:1
+func SplitKeybind(value string) (string, string, bool) {
+	for i := 0; i < len(value); i++ {
+		if value[i] != '=' {
+			continue
+		}
+		if i == 0 || value[i-1] == '+' || value[i-1] == '>' || value[i-1] == ':' {
+			// the = is the key itself
+			continue
+		}
+		return value[:i], value[i+1:], true
+	}
+	return "", "", false
+}
+
+func checkTrigger(trigger string) error {
+	// prefixes change how the binding is matched, eg global:ctrl+a
+	for {
+		prefix, rest, ok := strings.Cut(trigger, ":")
+		if !ok || !slices.Contains(keybindPrefixes, prefix) {
+			break
+		}
+		trigger = rest
+	}
+
+	// sequences are separated by >, eg ctrl+a>n
+	for _, step := range strings.Split(trigger, ">") {
+		if step == "" {
+			return fmt.Errorf("empty key in trigger %q", trigger)
+		}
+		parts := strings.Split(step, "+")
+		// a trailing + is the plus key, eg ctrl++
+		if strings.HasSuffix(step, "++") {
+			parts = append(parts[:len(parts)-2], "+")
+		}
+		for i, part := range parts {
+			if part == "" {
+				return fmt.Errorf("empty key in trigger %q", trigger)
+			}
+			if i < len(parts)-1 && !slices.Contains(keybindModifiers, strings.ToLower(part)) {
+				return fmt.Errorf("unknown modifier %q in trigger %q", part, trigger)
+			}
+		}
+	}
+	return nil
+}
+
+func checkAction(action string) error {
+	name, parameter, hasParameter := splitAction(action)
+	takesParameter, ok := actions[name]
+	if !ok {
+		return fmt.Errorf("unknown keybind action %q", name)
+	}
+	if hasParameter && !takesParameter {
+		return fmt.Errorf("keybind action %s does not take a parameter", name)
+	}
+	if !hasParameter && takesParameter && !optionalParameter[name] {
+		return fmt.Errorf("keybind action %s needs a parameter, eg %s:<value>", name, name)
+	}
+	if hasParameter && parameter == "" && name != "text" {
+		return fmt.Errorf("keybind action %s has an empty parameter", name)
+	}
+	return nil
+}
@@ -4,9 +4,11 @@ import (
 	"bufio"
 	"flag"
 	"fmt"
+	"ghostty-ghost/ghostty"
 	"ghostty-ghost/parser"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 const (
@@ -14,8 +16,13 @@ const (
 	colorRed    = "\033[31m"
 	colorYellow = "\033[33m"
 	colorGreen  = "\033[32m"
+	colorCyan   = "\033[36m"
+	colorBold   = "\033[1m"
 )
 
+// exit code of a dry run that would change the target config
+const exitChangesPending = 2
+
 func colorError(message string) string {
 	return fmt.Sprintf("%sERROR: %s%s", colorRed, message, colorReset)
 }
@@ -33,6 +40,21 @@ type TerminalConfig struct {
 	path string
 }
 
+// options that change what a conversion produces besides the config itself
+type conversionOptions struct {
+	reportFormat string
+	reportFile   string
+	dryRun       bool
+	merge        bool
+
+	// write the colors to a theme file, named themeName when it is set
+	themeFile bool
+	themeName string
+
+	// extra kitty themes to recognise a theme picked with the themes kitten by
+	kittyThemesDir string
+}
+
 func checkIfPathExists(path string) bool {
 	_, err := os.Stat(path)
 	return !os.IsNotExist(err)
@@ -52,32 +74,81 @@ func main() {
 
 	// Define custom usage text
 	flag.Usage = func() {
-		fmt.Printf("Usage: %s [options]\n\n", os.Args[0])
+		fmt.Printf("Usage: %s [options]\n", os.Args[0])
+		fmt.Printf("       %s validate [ghostty config...]\n", os.Args[0])
+		fmt.Printf("       %s check-keybinds [ghostty config...]\n\n", os.Args[0])
 		fmt.Println("Options:")
 		fmt.Println("  -f, --from    Terminal to convert from ((k) kitty, (a) alacritty)")
 		fmt.Println("  -s, --source  Path to source terminal config file")
 		fmt.Println("  -t, --target  Path to target ghostty config file")
+		fmt.Println("  --report      Print a conversion report (text, json, markdown)")
+		fmt.Println("  --report-file Write the conversion report to a file instead of stdout")
+		fmt.Println("  --dry-run     Show a diff of the changes without writing anything")
+		fmt.Println("  --merge       Update a managed block in the ghostty config instead of replacing it")
+		fmt.Println("  --theme-file  Write the colors to a theme in the themes directory and set theme")
+		fmt.Println("  --theme-name  Name of the theme written by --theme-file (implies --theme-file)")
+		fmt.Println("  --kitty-themes-dir  Directory of kitty themes to recognise the current kitty theme by")
 		fmt.Println("\nExample:")
 		fmt.Printf("  %s -f kitty -s ~/.config/kitty/kitty.conf -t ~/.config/ghostty/config\n", os.Args[0])
 		fmt.Println("\nIf no flags are specified, interactive mode will be used.")
+		fmt.Printf("A dry run exits with %d when the target config would change.\n", exitChangesPending)
 	}
 
 	// Define known terminal configs
 	terminals := []TerminalConfig{
 		{"kitty", filepath.Join(homeDir, ".config", "kitty", "kitty.conf")},
 		{"alacritty", filepath.Join(homeDir, ".config", "alacritty", "alacritty.toml")},
+		{"alacritty", filepath.Join(homeDir, ".config", "alacritty", "alacritty.yml")},
 	}
 
 	// define the default paths
 	defaultGhosttyPath := filepath.Join(homeDir, ".config", "ghostty", "config")
 
+	// subcommands come before the conversion flags
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "validate":
+			os.Exit(runValidate(os.Args[2:], defaultGhosttyPath))
+		case "check-keybinds":
+			os.Exit(runCheckKeybinds(os.Args[2:], defaultGhosttyPath))
+		}
+	}
+
 	// Parse the flags
 	fromTerminal := flag.String("f", "", "Terminal to convert from (k kitty, a alacritty)")
 	sourcePath := flag.String("s", "", "Path to source terminal config")
 	targetPath := flag.String("t", defaultGhosttyPath, "Path to ghostty config")
+	reportFormat := flag.String("report", "", "Conversion report format (text, json, markdown)")
+	reportFile := flag.String("report-file", "", "Path to write the conversion report to")
+	dryRun := flag.Bool("dry-run", false, "Show the changes without writing the ghostty config")
+	merge := flag.Bool("merge", false, "Merge into a managed block of the existing ghostty config")
+	themeFile := flag.Bool("theme-file", false, "Write the colors to a ghostty theme file")
+	themeName := flag.String("theme-name", "", "Name of the ghostty theme file")
+	kittyThemesDir := flag.String("kitty-themes-dir", "", "Directory of kitty themes to recognise the current theme by")
 
 	flag.Parse()
 
+	options := conversionOptions{
+		reportFormat: *reportFormat,
+		reportFile:   *reportFile,
+		dryRun:       *dryRun,
+		merge:        *merge,
+		themeFile:    *themeFile || *themeName != "",
+		themeName:    *themeName,
+
+		kittyThemesDir: *kittyThemesDir,
+	}
+	// a report file without a format gets the plain text report
+	if options.reportFile != "" && options.reportFormat == "" {
+		options.reportFormat = "text"
+	}
+	if options.reportFormat != "" {
+		if err := parser.CheckReportFormat(options.reportFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(err.Error()))
+			os.Exit(1)
+		}
+	}
+
 	// check what is installed
 	var availableTerminals []TerminalConfig
 	for _, terminal := range terminals {
@@ -114,11 +185,11 @@ func main() {
 		fmt.Printf("Selected terminal: %s\n", targetConfig.name)
 		fmt.Printf("Path: %s\n", targetConfig.path)
 
-		handleParseOfConfig(targetConfig.name, targetConfig.path, *targetPath, defaultGhosttyPath)
+		handleParseOfConfig(targetConfig.name, targetConfig.path, *targetPath, defaultGhosttyPath, options)
 
 	} else { // If flags have been used
 
-		handleParseOfConfig(*fromTerminal, *sourcePath, *targetPath, defaultGhosttyPath)
+		handleParseOfConfig(*fromTerminal, *sourcePath, *targetPath, defaultGhosttyPath, options)
 
 	}
 	// Print the target pathfmt.Printf("Successfully converted configuration to: %s\n", *targetPath)
@@ -130,7 +201,7 @@ func main() {
 }
 
 // handle pasring the config file
-func handleParseOfConfig(fromTerminal, sourcePath, targetPath, defaultGhosttyPath string) {
+func handleParseOfConfig(fromTerminal, sourcePath, targetPath, defaultGhosttyPath string, options conversionOptions) {
 	// do checks on the source path
 	if sourcePath == "" {
 		fmt.Fprintf(os.Stderr, "%s\n", colorWarning("The default source terminal path does not exist, please use ghostty-ghost -h for help"))
@@ -156,6 +227,10 @@ func handleParseOfConfig(fromTerminal, sourcePath, targetPath, defaultGhosttyPat
 		os.Exit(1)
 	}
 
+	if kittyParser, ok := configParser.(*parser.KittyParser); ok && options.kittyThemesDir != "" {
+		kittyParser.AddThemesDir(options.kittyThemesDir)
+	}
+
 	// Parse config
 	config, err := configParser.Parse(sourcePath)
 	if err != nil {
@@ -170,16 +245,240 @@ func handleParseOfConfig(fromTerminal, sourcePath, targetPath, defaultGhosttyPat
 		os.Exit(1)
 	}
 
+	themes := configParser.Themes()
+	if options.themeFile {
+		theme, err := parser.ExtractTheme(ghosttyConfig, sourcePath, options.themeName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Keeping the colors in the config: %v", err)))
+		} else if theme != nil {
+			themes = append(themes, *theme)
+		}
+	}
+
+	// check the converted config against the ghostty schema, the entries
+	// point back at the source config
+	for _, problem := range parser.ValidateGhostty(ghosttyConfig) {
+		if problem.Severity == parser.SeverityError {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning("Ghostty will reject "+problem.String()))
+		}
+	}
+	for _, theme := range themes {
+		for _, problem := range parser.ValidateGhostty(theme.Config) {
+			if problem.Severity == parser.SeverityError {
+				fmt.Fprintf(os.Stderr, "%s\n", colorWarning("Ghostty will reject "+problem.String()))
+			}
+		}
+	}
+	for _, problem := range parser.CheckKeybinds(ghosttyConfig, ghostty.Platform()) {
+		fmt.Fprintf(os.Stderr, "%s\n", colorWarning("Keybind "+problem.String()))
+	}
+
 	// write the ghossty config to the target path, if no target path is provided use the default path
 	// Write the config
 	if targetPath == "" {
 		targetPath = defaultGhosttyPath
 	}
 
-	err = configParser.Write(targetPath, ghosttyConfig)
+	if options.dryRun {
+		changed, err := showDryRun(targetPath, ghosttyConfig, themes, options)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(fmt.Sprintf("Error comparing config: %v", err)))
+			os.Exit(1)
+		}
+		if options.reportFormat != "" {
+			if err := writeReport(configParser.Report(), options); err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", colorError(fmt.Sprintf("Error writing report: %v", err)))
+				os.Exit(1)
+			}
+		}
+		if changed {
+			os.Exit(exitChangesPending)
+		}
+		os.Exit(0)
+	}
+
+	if options.merge {
+		err = parser.WriteMergedGhosttyConfig(targetPath, ghosttyConfig)
+	} else {
+		err = configParser.Write(targetPath, ghosttyConfig)
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "%s\n", colorError(fmt.Sprintf("Error writing config: %v\n", err)))
 		os.Exit(1)
 	}
+	for _, theme := range themes {
+		if err := parser.WriteGhosttyTheme(targetPath, theme); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(fmt.Sprintf("Error writing theme %s: %v", theme.Name, err)))
+			os.Exit(1)
+		}
+		fmt.Printf("%s %s\n", "🎨", "Theme saved to: "+parser.ThemePath(targetPath, theme.Name))
+	}
+
+	if options.reportFormat != "" {
+		if err := writeReport(configParser.Report(), options); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(fmt.Sprintf("Error writing report: %v", err)))
+			os.Exit(1)
+		}
+	}
 
 }
+
+// validate ghostty configs, returns the exit code
+func runValidate(paths []string, defaultGhosttyPath string) int {
+	if len(paths) == 0 {
+		paths = []string{defaultGhosttyPath}
+	}
+
+	exitCode := 0
+	for _, path := range paths {
+		problems, err := parser.ValidateGhosttyFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(err.Error()))
+			exitCode = 1
+			continue
+		}
+
+		for _, problem := range problems {
+			if problem.Severity == parser.SeverityError {
+				fmt.Fprintf(os.Stderr, "%s\n", colorError(problem.String()))
+			} else {
+				fmt.Fprintf(os.Stderr, "%s\n", colorWarning(problem.String()))
+			}
+		}
+		if parser.HasErrors(problems) {
+			exitCode = 1
+			continue
+		}
+		fmt.Printf("%s %s\n", "✅", colorSuccess(path+" is valid"))
+	}
+	return exitCode
+}
+
+// check the keybinds of ghostty configs against each other and the default
+// keybinds, returns the exit code
+func runCheckKeybinds(paths []string, defaultGhosttyPath string) int {
+	if len(paths) == 0 {
+		paths = []string{defaultGhosttyPath}
+	}
+
+	exitCode := 0
+	for _, path := range paths {
+		config, err := parser.LoadGhosttyConfig(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorError(err.Error()))
+			exitCode = 1
+			continue
+		}
+
+		problems := parser.CheckKeybinds(config, ghostty.Platform())
+		for _, problem := range problems {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(problem.String()))
+		}
+		if len(problems) > 0 {
+			exitCode = 1
+			continue
+		}
+		fmt.Printf("%s %s\n", "✅", colorSuccess(path+" has no keybind conflicts"))
+	}
+	return exitCode
+}
+
+// print a diff between the target config and themes and what would be
+// written, returns whether anything would change
+func showDryRun(targetPath string, ghosttyConfig *parser.Document, themes []parser.Theme, options conversionOptions) (bool, error) {
+	current, err := os.ReadFile(targetPath)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	converted := parser.RenderGhostty(ghosttyConfig)
+	if options.merge {
+		converted, err = parser.MergeGhosttyConfig(targetPath, string(current), ghosttyConfig)
+		if err != nil {
+			return false, err
+		}
+	}
+	changed := showDiff(targetPath, string(current), converted)
+
+	for _, theme := range themes {
+		themePath := parser.ThemePath(targetPath, theme.Name)
+		current, err := os.ReadFile(themePath)
+		if err != nil && !os.IsNotExist(err) {
+			return false, err
+		}
+		if showDiff(themePath, string(current), parser.RenderGhostty(theme.Config)) {
+			changed = true
+		}
+	}
+	return changed, nil
+}
+
+// print the diff between the current and converted contents of path, returns
+// whether there is one
+func showDiff(path, current, converted string) bool {
+	diff := parser.UnifiedDiff(path, path+" (converted)", current, converted)
+	if diff == "" {
+		fmt.Printf("%s %s\n", "✅", "No changes, "+path+" is up to date")
+		return false
+	}
+
+	if isTerminal(os.Stdout) {
+		diff = colorDiff(diff)
+	}
+	fmt.Print(diff)
+	return true
+}
+
+// color the lines of a unified diff like git does
+func colorDiff(diff string) string {
+	lines := strings.SplitAfter(diff, "\n")
+	for i, line := range lines {
+		text := strings.TrimSuffix(line, "\n")
+		if text == "" {
+			continue
+		}
+		color := ""
+		switch {
+		case strings.HasPrefix(text, "--- "), strings.HasPrefix(text, "+++ "):
+			color = colorBold
+		case strings.HasPrefix(text, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(text, "-"):
+			color = colorRed
+		case strings.HasPrefix(text, "+"):
+			color = colorGreen
+		default:
+			continue
+		}
+		lines[i] = color + text + colorReset + strings.TrimPrefix(line, text)
+	}
+	return strings.Join(lines, "")
+}
+
+// check if the file is a terminal rather than a pipe or file
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// print the conversion report or save it to the report file
+func writeReport(report *parser.Report, options conversionOptions) error {
+	rendered, err := report.Render(options.reportFormat)
+	if err != nil {
+		return err
+	}
+
+	if options.reportFile == "" {
+		fmt.Printf("\n%s", rendered)
+		return nil
+	}
+
+	if err := os.WriteFile(options.reportFile, []byte(rendered), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("%s %s\n", "📋", "Conversion report saved to: "+options.reportFile)
+	return nil
+}
@@ -0,0 +1,645 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type AlacrittyParser struct {
+	configPath string
+	report     *Report
+
+	// every file that was loaded, imports first and the main config last
+	files []string
+}
+
+// alacritty refuses to follow imports deeper than this
+const maxImportDepth = 5
+
+// constructor for alacrittyParser
+func NewAlacrittyParser(configPath string) *AlacrittyParser {
+	return &AlacrittyParser{configPath: configPath}
+}
+
+// position of a setting in a source file
+type sourcePos struct {
+	file string
+	line int
+}
+
+// alacrittyTree is a decoded config with the origin of every dotted path
+type alacrittyTree struct {
+	root    map[string]any
+	origins map[string]sourcePos
+}
+
+// Parse decodes the toml config and its imports into a tree and a flattened
+// document
+func (a *AlacrittyParser) Parse(SourceFilepath string) (*Document, error) {
+	a.files = nil
+	tree, err := a.load(SourceFilepath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewDocument()
+	config.Tree = tree.root
+	flattenTree(config, tree.root, "", tree.origins)
+	sortEntriesByOrigin(config, a.files)
+
+	return config, nil
+}
+
+// load a file and everything it imports. Imports are merged in order, so
+// later imports override earlier ones and the file itself overrides them all.
+// stack holds the files currently being loaded, to detect cycles.
+func (a *AlacrittyParser) load(SourceFilepath string, stack []string) (*alacrittyTree, error) {
+	data, err := os.ReadFile(SourceFilepath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open config file: %w", err)
+	}
+
+	var decoded *decodedTree
+	if isLegacyAlacrittyConfig(SourceFilepath) {
+		decoded, err = decodeYAML(SourceFilepath, data)
+		if err == nil {
+			translateLegacyAlacritty(decoded)
+		}
+	} else {
+		decoded, err = decodeTOML(SourceFilepath, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	file := &alacrittyTree{root: decoded.Root, origins: make(map[string]sourcePos, len(decoded.Lines))}
+	for path, line := range decoded.Lines {
+		file.origins[path] = sourcePos{file: SourceFilepath, line: line}
+	}
+
+	imports := a.imports(file, SourceFilepath)
+	stack = append(stack, absolutePath(SourceFilepath))
+
+	merged := &alacrittyTree{root: map[string]any{}, origins: map[string]sourcePos{}}
+	for _, importPath := range imports {
+		if slices.Contains(stack, absolutePath(importPath)) {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Import cycle detected, skipping %s (imported from %s)", importPath, SourceFilepath)))
+			continue
+		}
+		if len(stack) > maxImportDepth {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Exceeded maximum import depth of %d, skipping %s", maxImportDepth, importPath)))
+			continue
+		}
+		if _, err := os.Stat(importPath); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Config import not found: %s", importPath)))
+			continue
+		}
+
+		imported, err := a.load(importPath, stack)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Failed to load import: %v", err)))
+			continue
+		}
+		merged.merge(imported)
+	}
+	merged.merge(file)
+
+	a.files = append(a.files, SourceFilepath)
+	return merged, nil
+}
+
+// pre 0.13 alacritty configs are yaml
+func isLegacyAlacrittyConfig(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// legacy yaml settings and the path they moved to in the toml config
+var legacyAlacrittyKeys = [][2]string{
+	{"key_bindings", "keyboard.bindings"},
+	{"mouse_bindings", "mouse.bindings"},
+	{"import", "general.import"},
+	{"shell", "terminal.shell"},
+	{"working_directory", "general.working_directory"},
+	{"live_config_reload", "general.live_config_reload"},
+	{"ipc_socket", "general.ipc_socket"},
+	{"draw_bold_text_with_bright_colors", "colors.draw_bold_text_with_bright_colors"},
+	{"background_opacity", "window.opacity"},
+	{"window.background_opacity", "window.opacity"},
+	{"dynamic_title", "window.dynamic_title"},
+	{"window.gtk_theme_variant", "window.decorations_theme_variant"},
+	{"visual_bell", "bell"},
+}
+
+// move legacy yaml settings to their current names, a setting already using
+// the current name wins over the legacy one
+func translateLegacyAlacritty(decoded *decodedTree) {
+	for _, rename := range legacyAlacrittyKeys {
+		from, to := rename[0], rename[1]
+		value, ok := lookupTOML(decoded.Root, from)
+		if !ok {
+			continue
+		}
+
+		fromKeys := strings.Split(from, ".")
+		parent, _ := lookupTOML(decoded.Root, strings.Join(fromKeys[:len(fromKeys)-1], "."))
+		if len(fromKeys) == 1 {
+			parent = decoded.Root
+		}
+		if table, ok := parent.(map[string]any); ok {
+			delete(table, fromKeys[len(fromKeys)-1])
+		}
+
+		if _, exists := lookupTOML(decoded.Root, to); exists {
+			continue
+		}
+
+		// create the tables on the way to the new path
+		table := decoded.Root
+		toKeys := strings.Split(to, ".")
+		for _, key := range toKeys[:len(toKeys)-1] {
+			next, ok := table[key].(map[string]any)
+			if !ok {
+				next = map[string]any{}
+				table[key] = next
+			}
+			table = next
+		}
+		table[toKeys[len(toKeys)-1]] = value
+
+		moved := map[string]int{}
+		for path, line := range decoded.Lines {
+			if path == from || strings.HasPrefix(path, from+".") {
+				delete(decoded.Lines, path)
+				moved[to+path[len(from):]] = line
+			}
+		}
+		for path, line := range moved {
+			decoded.Lines[path] = line
+		}
+	}
+}
+
+// take the import list out of a file and resolve every path. The newer
+// general.import is used when present, otherwise the top level import.
+func (a *AlacrittyParser) imports(file *alacrittyTree, SourceFilepath string) []string {
+	var imports any
+	if general, ok := file.root["general"].(map[string]any); ok {
+		if value, ok := general["import"]; ok {
+			imports = value
+			delete(general, "import")
+		}
+	}
+	if value, ok := file.root["import"]; ok {
+		if imports == nil {
+			imports = value
+		}
+		delete(file.root, "import")
+	}
+	file.deleteOrigins("general.import")
+	file.deleteOrigins("import")
+
+	paths, ok := imports.([]any)
+	if !ok {
+		if imports != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Ignoring import in %s, expected an array of paths", SourceFilepath)))
+		}
+		return nil
+	}
+
+	var resolved []string
+	for _, value := range paths {
+		importPath, ok := value.(string)
+		if !ok {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Ignoring invalid import %v in %s", value, SourceFilepath)))
+			continue
+		}
+		resolved = append(resolved, resolveImportPath(importPath, filepath.Dir(SourceFilepath)))
+	}
+	return resolved
+}
+
+// absolute form of a path for comparisons, falls back to the path itself
+func absolutePath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// expand ~ and resolve relative paths against the importing file's directory
+func resolveImportPath(importPath, configDir string) string {
+	if importPath == "~" || strings.HasPrefix(importPath, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			importPath = filepath.Join(homeDir, importPath[1:])
+		} else {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Could not get home directory: %v", err)))
+		}
+	}
+	if !filepath.IsAbs(importPath) {
+		importPath = filepath.Join(configDir, importPath)
+	}
+	return filepath.Clean(importPath)
+}
+
+// merge src into t the way alacritty merges imports: tables are merged
+// recursively, arrays are appended and any other value in src wins
+func (t *alacrittyTree) merge(src *alacrittyTree) {
+	t.mergeTable(t.root, src.root, "", src)
+}
+
+func (t *alacrittyTree) mergeTable(dst, srcTable map[string]any, prefix string, src *alacrittyTree) {
+	for key, value := range srcTable {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch srcValue := value.(type) {
+		case map[string]any:
+			if dstValue, ok := dst[key].(map[string]any); ok {
+				t.mergeTable(dstValue, srcValue, path, src)
+				continue
+			}
+		case []any:
+			if dstValue, ok := dst[key].([]any); ok {
+				// appended elements keep their origin under their new index
+				offset := len(dstValue)
+				for i := range srcValue {
+					t.copyOrigins(src, fmt.Sprintf("%s.%d", path, i), fmt.Sprintf("%s.%d", path, i+offset))
+				}
+				dst[key] = append(dstValue, srcValue...)
+				continue
+			}
+		}
+
+		t.deleteOrigins(path)
+		t.copyOrigins(src, path, path)
+		dst[key] = value
+	}
+}
+
+// copy the origin of a path and everything below it from src
+func (t *alacrittyTree) copyOrigins(src *alacrittyTree, from, to string) {
+	for path, pos := range src.origins {
+		if path == from {
+			t.origins[to] = pos
+		} else if strings.HasPrefix(path, from+".") {
+			t.origins[to+path[len(from):]] = pos
+		}
+	}
+}
+
+// drop the origin of a path and everything below it
+func (t *alacrittyTree) deleteOrigins(prefix string) {
+	for path := range t.origins {
+		if path == prefix || strings.HasPrefix(path, prefix+".") {
+			delete(t.origins, path)
+		}
+	}
+}
+
+// flatten a tree into dotted key entries. Arrays of tables, like
+// keyboard.bindings, become one entry per table.
+func flattenTree(config *Document, table map[string]any, prefix string, origins map[string]sourcePos) {
+	for key, value := range table {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case nil:
+			// a yaml null such as shell: ~ leaves the setting at its default
+			continue
+		case map[string]any:
+			flattenTree(config, v, path, origins)
+		case []any:
+			if isTableArray(v) {
+				for i, element := range v {
+					pos := origins[fmt.Sprintf("%s.%d", path, i)]
+					config.Add(path, formatTOMLValue(element), pos.file, pos.line)
+				}
+				continue
+			}
+			pos := origins[path]
+			config.Add(path, formatTOMLValue(v), pos.file, pos.line)
+		default:
+			pos := origins[path]
+			config.Add(path, formatTOMLValue(v), pos.file, pos.line)
+		}
+	}
+}
+
+func isTableArray(array []any) bool {
+	if len(array) == 0 {
+		return false
+	}
+	for _, element := range array {
+		if _, ok := element.(map[string]any); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// order entries by file, in the order files were loaded, then by line
+func sortEntriesByOrigin(config *Document, files []string) {
+	fileOrder := make(map[string]int, len(files))
+	for i, file := range files {
+		fileOrder[file] = i + 1
+	}
+	sort.SliceStable(config.Entries, func(i, j int) bool {
+		a, b := config.Entries[i], config.Entries[j]
+		if fileOrder[a.Source] != fileOrder[b.Source] {
+			return fileOrder[a.Source] < fileOrder[b.Source]
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Key < b.Key
+	})
+}
+
+// Report describes what happened to every setting in the last conversion
+func (a *AlacrittyParser) Report() *Report {
+	return a.report
+}
+
+// Themes are the theme files the last conversion refers to
+func (a *AlacrittyParser) Themes() []Theme {
+	return nil
+}
+
+// Implement the Write method
+func (a *AlacrittyParser) Write(filepath string, config *Document) error {
+	return writeGhosttyConfig(filepath, config)
+}
+
+func (a *AlacrittyParser) ConvertToGhostty(config *Document) (*Document, error) {
+	ghosttyConfig := NewDocument()
+	var unmapped []unmappedSetting
+	a.report = NewReport("alacritty")
+
+	// styles fall back to the normal family and tables and shell args are
+	// read from the tree, so their transforms are built per config
+	fontCodex := alacrittyFontCodex(config)
+	treeCodex := alacrittyTreeCodex(config)
+	_, hasShellProgram := config.Get("terminal.shell.program")
+
+	for _, entry := range config.Entries {
+		if entry.Key == "terminal.shell.args" && hasShellProgram {
+			a.report.add(entry, StatusTransformed, []string{"command"}, "joined with terminal.shell.program")
+			continue
+		}
+
+		settings, handled, err := convertEntry(alacrittyToGhostty, ghosttyConfig, entry)
+		if !handled {
+			settings, handled, err = convertEntry(fontCodex, ghosttyConfig, entry)
+		}
+		if !handled {
+			settings, handled, err = convertEntry(treeCodex, ghosttyConfig, entry)
+		}
+		if !handled {
+			// handle unmapped keys
+			unmapped = append(unmapped, unmappedSetting{entry: entry})
+			a.report.add(entry, StatusUnsupported, nil, "no ghostty equivalent")
+			continue
+		}
+		if err != nil {
+			unmapped = append(unmapped, unmappedSetting{entry, err.Error()})
+		}
+		a.report.addConverted(entry, settings, err)
+	}
+
+	mergeFlags(ghosttyConfig, "bell-features")
+	appendUnmapped(ghosttyConfig, unmapped)
+
+	return ghosttyConfig, nil
+}
+
+// alacritty settings by their dotted toml path
+var alacrittyToGhostty = map[string]valueTransform{
+	// Font Settings
+	"font.normal.family":      rename("font-family"),
+	"font.size":               number("font-size"),
+	"font.italic.family":      rename("font-family-italic"),
+	"font.bold_italic.family": rename("font-family-bold-italic"),
+	"font.bold.family":        rename("font-family-bold"),
+
+	// extra space around each cell and where glyphs sit in it, in pixels
+	"font.offset.x": unlessDefault("0", pixels("adjust-cell-width")),
+	"font.offset.y": unlessDefault("0", approximately("alacritty adds the space above the text, ghostty keeps the text centred",
+		pixels("adjust-cell-height"))),
+	"font.glyph_offset.x": unlessDefault("0", unsupported("ghostty can't move glyphs sideways within the cell")),
+	"font.glyph_offset.y": unlessDefault("0", pixels("adjust-font-baseline")),
+
+	// Cursor Settings
+	"cursor.style.shape": enum("cursor-style", map[string]string{
+		"block":     "block",
+		"beam":      "bar",
+		"underline": "underline",
+		"hollow":    "block_hollow",
+	}),
+	"cursor.style.blinking": enum("cursor-style-blink", map[string]string{
+		"never":  "false",
+		"off":    "false",
+		"on":     "true",
+		"always": "true",
+	}),
+	"cursor.vi_mode_style.shape":    unsupported("ghostty has no vi mode"),
+	"cursor.vi_mode_style.blinking": unsupported("ghostty has no vi mode"),
+	"colors.cursor.cursor":          alacrittyCellColor("cursor-color"),
+	"colors.cursor.text":            alacrittyCellColor("cursor-text"),
+
+	// Colors
+	"colors.primary.background":   color("background"),
+	"colors.primary.foreground":   color("foreground"),
+	"colors.selection.text":       alacrittyCellColor("selection-foreground"),
+	"colors.selection.background": alacrittyCellColor("selection-background"),
+
+	// Window Layout
+	"window.padding.x":          number("window-padding-x"),
+	"window.padding.y":          number("window-padding-y"),
+	"window.dimensions.columns": unlessDefault("0", number("window-width")),
+	"window.dimensions.lines":   unlessDefault("0", number("window-height")),
+	"window.title":              rename("title"),
+	"window.decorations": enumSettings(map[string][]setting{
+		"full":        nil,
+		"none":        {{key: "window-decoration", value: "false"}},
+		"transparent": {{key: "macos-titlebar-style", value: "transparent", approx: "only applies on macOS"}},
+		"buttonless":  {{key: "macos-titlebar-style", value: "hidden", approx: "ghostty hides the whole titlebar, not just the buttons"}},
+	}),
+	"window.opacity": number("background-opacity"),
+	// if alacritty has a blur set it to default 10 in ghostty
+	"window.blur": enumSettings(map[string][]setting{
+		"true":  {{key: "background-blur-radius", value: "10", approx: "alacritty has no blur radius, using 10"}},
+		"false": nil,
+	}),
+	"window.option_as_alt": enum("macos-option-as-alt", map[string]string{
+		"none":      "false",
+		"onlyleft":  "left",
+		"onlyright": "right",
+		"both":      "true",
+	}),
+
+	// Scrollback & Mouse
+	"scrolling.history":      scrollbackLines("scrollback-limit"),
+	"scrolling.multiplier":   number("mouse-scroll-multiplier"),
+	"mouse.hide_when_typing": boolean("mouse-hide-while-typing"),
+	"selection.save_to_clipboard": enum("copy-on-select", map[string]string{
+		"true":  "clipboard",
+		"false": "",
+	}),
+
+	// Clipboard Handling, alacritty has one setting for both directions
+	"terminal.osc52": enumSettings(map[string][]setting{
+		"disabled":  {{key: "clipboard-read", value: "deny"}, {key: "clipboard-write", value: "deny"}},
+		"onlycopy":  {{key: "clipboard-read", value: "deny"}, {key: "clipboard-write", value: "allow"}},
+		"onlypaste": {{key: "clipboard-read", value: "allow"}, {key: "clipboard-write", value: "deny"}},
+		"copypaste": {{key: "clipboard-read", value: "allow"}, {key: "clipboard-write", value: "allow"}},
+	}),
+
+	// Shell
+	"terminal.shell":            rename("command"),
+	"terminal.shell.args":       unsupported("ghostty needs the program to run the args with"),
+	"general.working_directory": rename("working-directory"),
+
+	// Normal colors (0-7)
+	"colors.normal.black":   paletteColor(0),
+	"colors.normal.red":     paletteColor(1),
+	"colors.normal.green":   paletteColor(2),
+	"colors.normal.yellow":  paletteColor(3),
+	"colors.normal.blue":    paletteColor(4),
+	"colors.normal.magenta": paletteColor(5),
+	"colors.normal.cyan":    paletteColor(6),
+	"colors.normal.white":   paletteColor(7),
+
+	// Bright colors (8-15)
+	"colors.bright.black":   paletteColor(8),
+	"colors.bright.red":     paletteColor(9),
+	"colors.bright.green":   paletteColor(10),
+	"colors.bright.yellow":  paletteColor(11),
+	"colors.bright.blue":    paletteColor(12),
+	"colors.bright.magenta": paletteColor(13),
+	"colors.bright.cyan":    paletteColor(14),
+	"colors.bright.white":   paletteColor(15),
+
+	// Bell, alacritty only has a visual bell
+	"bell.duration":        alacrittyBellDuration,
+	"bell.animation":       unlessDefault("Linear", unsupported("ghostty doesn't animate the bell")),
+	"bell.color":           unlessDefault("#ffffff", unlessDefault("0xffffff", unsupported("ghostty's bell border has no color setting"))),
+	"bell.command":         unlessDefault("None", unsupported("ghostty can't run a command on the bell")),
+	"bell.command.program": unsupported("ghostty can't run a command on the bell"),
+	"bell.command.args":    unsupported("ghostty can't run a command on the bell"),
+}
+
+// alacrittyTreeCodex returns the transforms that read the document tree:
+// arrays of tables, one entry per table, and the shell with its args
+func alacrittyTreeCodex(config *Document) map[string]valueTransform {
+	return map[string]valueTransform{
+		"keyboard.bindings":      alacrittyTable(config, "keyboard.bindings", alacrittyKeyBinding),
+		"mouse.bindings":         alacrittyTable(config, "mouse.bindings", alacrittyMouseBinding),
+		"colors.indexed_colors":  alacrittyTable(config, "colors.indexed_colors", alacrittyIndexedColor),
+		"terminal.shell.program": alacrittyShell(config),
+	}
+}
+
+// alacrittyShell joins terminal.shell.program and its args into one ghostty
+// command, ghostty runs it with /bin/sh -c so args are quoted for the shell
+func alacrittyShell(config *Document) valueTransform {
+	args, _ := treeValue(config.Tree, "terminal.shell.args").([]any)
+	return func(value string) ([]setting, error) {
+		words := []string{shellQuote(value)}
+		for _, arg := range args {
+			text, ok := arg.(string)
+			if !ok {
+				text = formatTOMLValue(arg)
+			}
+			words = append(words, shellQuote(text))
+		}
+		return []setting{{key: "command", value: strings.Join(words, " ")}}, nil
+	}
+}
+
+// shellQuote single quotes a word with spaces or other characters the shell
+// treats specially
+func shellQuote(word string) string {
+	if word != "" && !strings.ContainsFunc(word, func(c rune) bool {
+		return !strings.ContainsRune("-_./:=+,@%", c) && !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	}) {
+		return word
+	}
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// alacrittyTable passes convert the table of the array at path in the
+// document tree that an entry was flattened from
+func alacrittyTable(config *Document, path string, convert func(string, map[string]any) ([]setting, error)) valueTransform {
+	// flattenTree renders each table with formatTOMLValue, so that text
+	// finds the table again
+	tables := make(map[string]map[string]any)
+	if array, ok := treeValue(config.Tree, path).([]any); ok {
+		for _, element := range array {
+			if table, ok := element.(map[string]any); ok {
+				tables[formatTOMLValue(table)] = table
+			}
+		}
+	}
+
+	return func(value string) ([]setting, error) {
+		table, ok := tables[value]
+		if !ok {
+			return nil, unrepresentable(value, "expected a table")
+		}
+		return convert(value, table)
+	}
+}
+
+// treeValue returns the value at a dotted path of tree, nil when it isn't set
+func treeValue(tree map[string]any, path string) any {
+	var value any = tree
+	for _, key := range strings.Split(path, ".") {
+		table, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = table[key]
+	}
+	return value
+}
+
+// alacrittyCellColor converts a color that can be CellForeground or
+// CellBackground, the colors of the cell under the cursor or selection
+func alacrittyCellColor(key string) valueTransform {
+	return specialColor(map[string][]setting{
+		"cellforeground": {{key: key, value: cellForeground}},
+		"cellbackground": {{key: key, value: cellBackground}},
+	}, color(key))
+}
+
+// alacrittyIndexedColor converts an { index = N, color = "..." } entry of
+// colors.indexed_colors to a palette entry
+func alacrittyIndexedColor(value string, entry map[string]any) ([]setting, error) {
+	indexText, hasIndex := bindingField(entry, "index")
+	color, hasColor := bindingField(entry, "color")
+	if !hasIndex || !hasColor {
+		return nil, unrepresentable(value, "expected an index and a color")
+	}
+
+	index, err := strconv.Atoi(indexText)
+	if err != nil {
+		return nil, unrepresentable(value, "expected a whole number index")
+	}
+	if index < 16 {
+		// the first 16 come from colors.normal and colors.bright
+		return nil, unrepresentable(value, "alacritty ignores indexed colors below 16")
+	}
+	return paletteColor(index)(color)
+}
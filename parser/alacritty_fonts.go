@@ -0,0 +1,39 @@
+package parser
+
+import "strings"
+
+// the alacritty font tables, their ghostty key suffix and default style
+var alacrittyFontStyles = []struct {
+	table, suffix, style string
+}{
+	{"normal", "", "Regular"},
+	{"bold", "-bold", "Bold"},
+	{"italic", "-italic", "Italic"},
+	{"bold_italic", "-bold-italic", "Bold Italic"},
+}
+
+// alacrittyFontCodex returns the transforms for the font.<table>.style keys.
+// Alacritty uses the normal family for tables that only set a style, so the
+// normal family is repeated for them. Built per conversion as it depends on
+// the families the config sets.
+func alacrittyFontCodex(config *Document) map[string]valueTransform {
+	normalFamily, _ := config.Get("font.normal.family")
+
+	codex := make(map[string]valueTransform)
+	for _, font := range alacrittyFontStyles {
+		_, hasFamily := config.Get("font." + font.table + ".family")
+		codex["font."+font.table+".style"] = func(value string) ([]setting, error) {
+			value = strings.TrimSpace(value)
+			if strings.EqualFold(value, font.style) {
+				return nil, nil
+			}
+
+			var settings []setting
+			if font.suffix != "" && !hasFamily && normalFamily != "" {
+				settings = append(settings, setting{key: "font-family" + font.suffix, value: normalFamily})
+			}
+			return append(settings, setting{key: "font-style" + font.suffix, value: value}), nil
+		}
+	}
+	return codex
+}
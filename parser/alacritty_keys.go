@@ -0,0 +1,235 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// bindingField returns a table field as a string, numbers such as key = 1
+// included
+func bindingField(binding map[string]any, name string) (string, bool) {
+	value, ok := binding[name]
+	if !ok {
+		return "", false
+	}
+	if text, ok := value.(string); ok {
+		return text, true
+	}
+	return formatTOMLValue(value), true
+}
+
+// alacrittyKeyBinding converts a [[keyboard.bindings]] entry to a keybind
+func alacrittyKeyBinding(value string, binding map[string]any) ([]setting, error) {
+	key, ok := bindingField(binding, "key")
+	if !ok {
+		return nil, unrepresentable(value, "binding has no key")
+	}
+
+	var approx []string
+	if mode, ok := bindingField(binding, "mode"); ok {
+		note, err := alacrittyBindingMode(mode)
+		if err != nil {
+			return nil, err
+		}
+		if note != "" {
+			approx = append(approx, note)
+		}
+	}
+
+	trigger, err := alacrittyTrigger(binding, key)
+	if err != nil {
+		return nil, err
+	}
+
+	action, note, err := alacrittyBindingAction(binding)
+	if err != nil {
+		return nil, err
+	}
+	if note != "" {
+		approx = append(approx, note)
+	}
+
+	return []setting{{key: "keybind", value: trigger + "=" + action, approx: strings.Join(approx, "; ")}}, nil
+}
+
+// alacrittyMouseBinding reports [[mouse.bindings]] entries, ghostty keybinds
+// are only triggered by keys
+func alacrittyMouseBinding(value string, binding map[string]any) ([]setting, error) {
+	mouse, _ := bindingField(binding, "mouse")
+	return nil, unrepresentable(value, "ghostty keybinds can't be triggered by the %s mouse button", mouse)
+}
+
+// modes alacritty bindings are limited to. Ghostty has no vi or search mode
+// so bindings that only apply outside of them always apply.
+func alacrittyBindingMode(mode string) (string, error) {
+	var dropped []string
+	for _, part := range strings.Split(mode, "|") {
+		part = strings.TrimSpace(part)
+		switch part {
+		case "":
+		case "~Vi", "~Search":
+			dropped = append(dropped, part)
+		default:
+			return "", unrepresentable(mode, "ghostty keybinds can't be limited to the %s mode", part)
+		}
+	}
+	if len(dropped) == 0 {
+		return "", nil
+	}
+	return fmt.Sprintf("ghostty has no vi or search mode, %s is always true", strings.Join(dropped, "|")), nil
+}
+
+// alacritty modifier names in ghostty form
+var alacrittyModifiers = map[string]string{
+	"control": "ctrl",
+	"ctrl":    "ctrl",
+	"shift":   "shift",
+	"alt":     "alt",
+	"option":  "alt",
+	"super":   "super",
+	"command": "super",
+	"none":    "",
+}
+
+// alacritty (winit) key names that differ from ghostty's, both the current
+// and the pre 0.13 spellings
+var alacrittyKeyNames = map[string]string{
+	"return":         "enter",
+	"back":           "backspace",
+	"arrowup":        "up",
+	"arrowdown":      "down",
+	"arrowleft":      "left",
+	"arrowright":     "right",
+	"pageup":         "page_up",
+	"pagedown":       "page_down",
+	"equals":         "equal",
+	"grave":          "grave_accent",
+	"lbracket":       "left_bracket",
+	"rbracket":       "right_bracket",
+	"add":            "kp_add",
+	"subtract":       "kp_subtract",
+	"numpadadd":      "kp_add",
+	"numpadsubtract": "kp_subtract",
+	"numpadmultiply": "kp_multiply",
+	"numpaddivide":   "kp_divide",
+	"numpaddecimal":  "kp_decimal",
+	"numpadenter":    "kp_enter",
+	"numpadequals":   "kp_equal",
+	"printscreen":    "print_screen",
+	"scrolllock":     "scroll_lock",
+	"capslock":       "caps_lock",
+	"numlock":        "num_lock",
+}
+
+// alacrittyTrigger builds the trigger from the key and its mods
+func alacrittyTrigger(binding map[string]any, key string) (string, error) {
+	var parts []string
+	if mods, ok := bindingField(binding, "mods"); ok {
+		for _, mod := range strings.Split(mods, "|") {
+			modifier, known := alacrittyModifiers[strings.ToLower(strings.TrimSpace(mod))]
+			if !known {
+				return "", unrepresentable(mods, "ghostty has no %s modifier", mod)
+			}
+			if modifier != "" && !slices.Contains(parts, modifier) {
+				parts = append(parts, modifier)
+			}
+		}
+	}
+
+	name, err := convertAlacrittyKey(key)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(parts, name), "+"), nil
+}
+
+// convertAlacrittyKey converts a winit key name to ghostty's
+func convertAlacrittyKey(key string) (string, error) {
+	lower := strings.ToLower(key)
+	if name, ok := alacrittyKeyNames[lower]; ok {
+		return name, nil
+	}
+
+	// Key1 and Numpad1 from the pre 0.13 names
+	if digit, ok := strings.CutPrefix(lower, "key"); ok && len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9' {
+		return digit, nil
+	}
+	if digit, ok := strings.CutPrefix(lower, "numpad"); ok && len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9' {
+		return "kp_" + digit, nil
+	}
+
+	if name, ok := ghosttyKeyName(lower); ok {
+		return name, nil
+	}
+	return "", unrepresentable(key, "ghostty has no key named %s", key)
+}
+
+// alacrittyBindingAction converts the action, chars or command of a binding
+func alacrittyBindingAction(binding map[string]any) (string, string, error) {
+	if command, ok := binding["command"]; ok {
+		return "", "", unrepresentable(formatTOMLValue(command), "ghostty keybinds can't run a command")
+	}
+	if chars, ok := bindingField(binding, "chars"); ok {
+		return sendAction(chars), "", nil
+	}
+
+	action, ok := bindingField(binding, "action")
+	if !ok {
+		return "", "", unrepresentable("", "binding has no action, chars or command")
+	}
+
+	// SelectTab1 to SelectTab9
+	if tab, ok := strings.CutPrefix(strings.ToLower(action), "selecttab"); ok && len(tab) == 1 && tab[0] >= '1' && tab[0] <= '9' {
+		return "goto_tab:" + tab, "", nil
+	}
+
+	translated, ok := alacrittyActions[strings.ToLower(action)]
+	if !ok {
+		return "", "", unrepresentable(action, "ghostty has no equivalent for the alacritty %s action", action)
+	}
+	if translated.action == "" {
+		return "", "", &unrepresentableError{reason: translated.approx}
+	}
+	return translated.action, translated.approx, nil
+}
+
+// alacrittyActions maps lowercase alacritty actions to ghostty, approx notes
+// the difference when there is one and an empty action has no equivalent
+var alacrittyActions = map[string]struct{ action, approx string }{
+	"paste":                  {"paste_from_clipboard", ""},
+	"copy":                   {"copy_to_clipboard", ""},
+	"pasteselection":         {"paste_from_selection", ""},
+	"copyselection":          {"copy_to_clipboard", "ghostty copies to the clipboard rather than the selection"},
+	"increasefontsize":       {"increase_font_size:1", ""},
+	"decreasefontsize":       {"decrease_font_size:1", ""},
+	"resetfontsize":          {"reset_font_size", ""},
+	"scrollpageup":           {"scroll_page_up", ""},
+	"scrollpagedown":         {"scroll_page_down", ""},
+	"scrollhalfpageup":       {"scroll_page_fractional:-0.5", ""},
+	"scrollhalfpagedown":     {"scroll_page_fractional:0.5", ""},
+	"scrolllineup":           {"scroll_page_lines:-1", ""},
+	"scrolllinedown":         {"scroll_page_lines:1", ""},
+	"scrolltotop":            {"scroll_to_top", ""},
+	"scrolltobottom":         {"scroll_to_bottom", ""},
+	"clearhistory":           {"clear_screen", "ghostty also clears the screen"},
+	"spawnnewinstance":       {"new_window", "ghostty opens the window in the running instance"},
+	"createnewwindow":        {"new_window", ""},
+	"createnewtab":           {"new_tab", ""},
+	"selectnexttab":          {"next_tab", ""},
+	"selectprevioustab":      {"previous_tab", ""},
+	"selectlasttab":          {"last_tab", ""},
+	"togglefullscreen":       {"toggle_fullscreen", ""},
+	"togglemaximized":        {"toggle_maximize", ""},
+	"togglesimplefullscreen": {"toggle_fullscreen", "set macos-non-native-fullscreen for the same behaviour"},
+	"hide":                   {"toggle_visibility", ""},
+	"quit":                   {"quit", ""},
+	"none":                   {"ignore", ""},
+	"receivechar":            {"unbind", ""},
+	"clearlognotice":         {"", "ghostty has no log notices"},
+	"togglevimode":           {"", "ghostty has no vi mode"},
+	"searchforward":          {"", "ghostty has no scrollback search keybind"},
+	"searchbackward":         {"", "ghostty has no scrollback search keybind"},
+	"minimize":               {"", "ghostty has no minimize action"},
+	"hideotherapplications":  {"", "ghostty has no action to hide other applications"},
+}
@@ -0,0 +1,83 @@
+package parser
+
+import "testing"
+
+// decode an inline toml table such as { key = "N", mods = "Control" }
+func decodeBindingTable(t *testing.T, table string) map[string]any {
+	t.Helper()
+	tree, err := decodeTOML("test.toml", []byte("binding = "+table))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tree.Root["binding"].(map[string]any)
+}
+
+func TestAlacrittyKeyBinding(t *testing.T) {
+	tests := []struct {
+		name       string
+		binding    string
+		want       string
+		wantApprox bool
+	}{
+		{"action", `{ key = "N", mods = "Control|Shift", action = "SpawnNewInstance" }`, "ctrl+shift+n=new_window", true},
+		{"modifier aliases", `{ key = "C", mods = "Command|Option", action = "Copy" }`, "super+alt+c=copy_to_clipboard", false},
+		{"repeated modifier", `{ key = "V", mods = "Control|Ctrl", action = "Paste" }`, "ctrl+v=paste_from_clipboard", false},
+		{"no mods", `{ key = "F11", action = "ToggleFullscreen" }`, "f11=toggle_fullscreen", false},
+		{"key names", `{ key = "PageUp", mods = "Shift", action = "ScrollPageUp" }`, "shift+page_up=scroll_page_up", false},
+		{"legacy key names", `{ key = "Key1", mods = "Alt", action = "SelectTab1" }`, "alt+1=goto_tab:1", false},
+		{"numeric key", `{ key = 1, mods = "Alt", action = "SelectTab1" }`, "alt+1=goto_tab:1", false},
+		{"chars", `{ key = "K", mods = "Super", chars = "\u001b[A" }`, "super+k=csi:A", false},
+		{"text chars", `{ key = "H", mods = "Alt", chars = "hi" }`, `alt+h=text:hi`, false},
+		{"none", `{ key = "N", mods = "Control", action = "None" }`, "ctrl+n=ignore", false},
+		{"receive char", `{ key = "M", mods = "Control", action = "ReceiveChar" }`, "ctrl+m=unbind", false},
+		{"not in vi mode", `{ key = "T", mods = "Control", mode = "~Vi", action = "CreateNewTab" }`, "ctrl+t=new_tab", true},
+		{"not in vi or search mode", `{ key = "T", mods = "Control", mode = "~Vi|~Search", action = "CreateNewTab" }`, "ctrl+t=new_tab", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := alacrittyKeyBinding(test.binding, decodeBindingTable(t, test.binding))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(settings) != 1 || settings[0].key != "keybind" || settings[0].value != test.want {
+				t.Fatalf("binding %s = %v, want keybind = %s", test.binding, settings, test.want)
+			}
+			if (settings[0].approx != "") != test.wantApprox {
+				t.Errorf("binding %s approximation = %q", test.binding, settings[0].approx)
+			}
+		})
+	}
+}
+
+func TestAlacrittyBindingUnrepresentable(t *testing.T) {
+	tests := []struct {
+		name    string
+		binding string
+	}{
+		{"no key", `{ mods = "Control", action = "Copy" }`},
+		{"no action", `{ key = "C", mods = "Control" }`},
+		{"unknown modifier", `{ key = "C", mods = "Hyper", action = "Copy" }`},
+		{"unknown key", `{ key = "NotAKey", action = "Copy" }`},
+		{"unknown action", `{ key = "C", action = "NotAnAction" }`},
+		{"action without equivalent", `{ key = "Space", mods = "Control|Shift", action = "ToggleViMode" }`},
+		{"command", `{ key = "E", mods = "Control", command = { program = "code" } }`},
+		{"vi mode only", `{ key = "Y", mode = "Vi", action = "Copy" }`},
+		{"search mode only", `{ key = "Enter", mode = "Search", action = "SearchConfirm" }`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := alacrittyKeyBinding(test.binding, decodeBindingTable(t, test.binding))
+			if err == nil {
+				t.Errorf("binding %s = %v, want an error", test.binding, settings)
+			}
+		})
+	}
+}
+
+func TestAlacrittyMouseBinding(t *testing.T) {
+	binding := `{ mouse = "Middle", action = "PasteSelection" }`
+	settings, err := alacrittyMouseBinding(binding, decodeBindingTable(t, binding))
+	if err == nil {
+		t.Errorf("mouse binding = %v, want an error", settings)
+	}
+}
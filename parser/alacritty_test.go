@@ -0,0 +1,228 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFlattenTreeSkipsNull(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want map[string]string
+	}{
+		{"empty value", "live_config_reload:\nfont:\n  size: 12\n", map[string]string{"font.size": "12"}},
+		{"tilde", "shell: ~\nfont:\n  size: 12\n", map[string]string{"font.size": "12"}},
+		{"null", "font:\n  normal:\n    family: null\n  size: 12\n", map[string]string{"font.size": "12"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeYAML("test.yml", []byte(test.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := NewDocument()
+			flattenTree(config, tree.Root, "", nil)
+			if config.Len() != len(test.want) {
+				t.Errorf("got %d entries, want %d", config.Len(), len(test.want))
+			}
+			for key, want := range test.want {
+				if got, _ := config.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAlacrittyShell(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want string
+	}{
+		{"program only", "[terminal.shell]\nprogram = \"/bin/zsh\"\n", "/bin/zsh"},
+		{"args", "[terminal.shell]\nprogram = \"/bin/zsh\"\nargs = [\"-l\", \"--login\"]\n", "/bin/zsh -l --login"},
+		{"args with spaces", "[terminal.shell]\nprogram = \"/bin/zsh\"\nargs = [\"-c\", \"tmux new -A\"]\n", "/bin/zsh -c 'tmux new -A'"},
+		{"quotes", "[terminal.shell]\nprogram = \"/bin/sh\"\nargs = [\"-c\", \"echo it's\"]\n", `/bin/sh -c 'echo it'\''s'`},
+		{"program with spaces", "[terminal.shell]\nprogram = \"/opt/my shell\"\n", "'/opt/my shell'"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeTOML("test.toml", []byte(test.toml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := NewDocument()
+			config.Tree = tree.Root
+			flattenTree(config, tree.Root, "", nil)
+
+			converted, err := NewAlacrittyParser("").ConvertToGhostty(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := converted.GetAll("command"); len(got) != 1 || got[0] != test.want {
+				t.Errorf("command = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestAlacrittyImports(t *testing.T) {
+	// alacritty.toml imports c1.toml, which imports c2.toml and so on
+	chain := map[string]string{}
+	for i := 1; i <= 6; i++ {
+		chain[fmt.Sprintf("c%d.toml", i)] = fmt.Sprintf("general.import = [\"c%d.toml\"]\n[env]\nC%d = \"loaded\"\n", i+1, i)
+	}
+	chain["alacritty.toml"] = "general.import = [\"c1.toml\"]\n"
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  map[string]string
+		all   map[string][]string
+	}{
+		{
+			"later imports override earlier ones",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"a.toml\", \"b.toml\"]\n",
+				"a.toml":         "[font]\nsize = 10\n",
+				"b.toml":         "[font]\nsize = 11\n",
+			},
+			map[string]string{"font.size": "11"},
+			nil,
+		},
+		{
+			"the file overrides its imports",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"a.toml\"]\n[font]\nsize = 12\n",
+				"a.toml":         "[font]\nsize = 10\n",
+			},
+			map[string]string{"font.size": "12"},
+			nil,
+		},
+		{
+			"tables merge recursively",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"a.toml\"]\n[font.normal]\nstyle = \"Medium\"\n",
+				"a.toml":         "[font.normal]\nfamily = \"Hack\"\n[font]\nsize = 10\n",
+			},
+			map[string]string{"font.normal.family": "Hack", "font.normal.style": "Medium", "font.size": "10"},
+			nil,
+		},
+		{
+			"arrays append",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"a.toml\"]\n[[keyboard.bindings]]\nkey = \"B\"\naction = \"Copy\"\n",
+				"a.toml":         "[[keyboard.bindings]]\nkey = \"A\"\naction = \"Paste\"\n",
+			},
+			nil,
+			map[string][]string{"keyboard.bindings": {`{ action = "Paste", key = "A" }`, `{ action = "Copy", key = "B" }`}},
+		},
+		{
+			"imports relative to the importing file",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"sub/a.toml\"]\n",
+				"sub/a.toml":     "general.import = [\"b.toml\"]\n",
+				"sub/b.toml":     "[font]\nsize = 10\n",
+			},
+			map[string]string{"font.size": "10"},
+			nil,
+		},
+		{
+			"legacy top level import",
+			map[string]string{
+				"alacritty.toml": "import = [\"a.toml\"]\n",
+				"a.toml":         "[font]\nsize = 10\n",
+			},
+			map[string]string{"font.size": "10"},
+			nil,
+		},
+		{
+			"cycle",
+			map[string]string{
+				"alacritty.toml": "general.import = [\"a.toml\"]\n[font]\nsize = 12\n",
+				"a.toml":         "general.import = [\"alacritty.toml\"]\n[font.normal]\nfamily = \"Hack\"\n",
+			},
+			map[string]string{"font.size": "12", "font.normal.family": "Hack"},
+			nil,
+		},
+		{
+			"missing import",
+			map[string]string{"alacritty.toml": "general.import = [\"missing.toml\"]\n[font]\nsize = 12\n"},
+			map[string]string{"font.size": "12"},
+			nil,
+		},
+		{
+			"maximum depth",
+			chain,
+			map[string]string{"env.C1": "loaded", "env.C5": "loaded", "env.C6": ""},
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeFiles(t, test.files)
+			configPath := filepath.Join(dir, "alacritty.toml")
+			config, err := NewAlacrittyParser(configPath).Parse(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for key, want := range test.want {
+				if got, _ := config.Get(key); got != want {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+			for key, want := range test.all {
+				if got := config.GetAll(key); !slices.Equal(got, want) {
+					t.Errorf("%s = %q, want %q", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAlacrittyDefaults(t *testing.T) {
+	tests := []struct {
+		name    string
+		toml    string
+		key     string
+		ghostty string
+		want    string
+		status  Status
+	}{
+		{"default columns", "[window.dimensions]\ncolumns = 0\n", "window.dimensions.columns", "window-width", "", StatusIgnoredAsDefault},
+		{"columns", "[window.dimensions]\ncolumns = 120\n", "window.dimensions.columns", "window-width", "120", StatusMapped},
+		{"default lines", "[window.dimensions]\nlines = 0\n", "window.dimensions.lines", "window-height", "", StatusIgnoredAsDefault},
+		{"default offset", "[font.offset]\nx = 0\n", "font.offset.x", "adjust-cell-width", "", StatusIgnoredAsDefault},
+		{"offset", "[font.offset]\nx = 2\n", "font.offset.x", "adjust-cell-width", "2", StatusMapped},
+		{"default in another case", "[bell]\ncommand = \"none\"\n", "bell.command", "", "", StatusIgnoredAsDefault},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeTOML("test.toml", []byte(test.toml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := NewDocument()
+			config.Tree = tree.Root
+			flattenTree(config, tree.Root, "", nil)
+
+			alacrittyParser := NewAlacrittyParser("")
+			converted, err := alacrittyParser.ConvertToGhostty(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.ghostty != "" {
+				if got, _ := converted.Get(test.ghostty); got != test.want {
+					t.Errorf("%s = %q, want %q", test.ghostty, got, test.want)
+				}
+			}
+			if got := reportStatus(alacrittyParser.Report(), test.key); got != test.status {
+				t.Errorf("%s status = %s, want %s", test.key, got, test.status)
+			}
+		})
+	}
+}
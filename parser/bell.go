@@ -0,0 +1,123 @@
+package parser
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// bellFeature turns one of ghostty's bell-features on or off, the entries are
+// combined into one by mergeFlags
+func bellFeature(feature string, enabled bool) setting {
+	if !enabled {
+		feature = "no-" + feature
+	}
+	return setting{key: "bell-features", value: feature}
+}
+
+// bellSwitch converts a yes/no setting to a bell feature
+func bellSwitch(feature string) valueTransform {
+	return func(value string) ([]setting, error) {
+		enabled, ok := parseBool(value)
+		if !ok {
+			return nil, unrepresentable(value, "expected yes or no")
+		}
+		return []setting{bellFeature(feature, enabled)}, nil
+	}
+}
+
+// the visual bell flashes the window, ghostty can draw a border until the
+// terminal is focused instead
+const visualBellApprox = "ghostty draws a border around the terminal until it is focused instead of flashing it"
+
+// kittyVisualBell converts visual_bell_duration, seconds optionally followed
+// by an easing function. Zero turns the visual bell off.
+func kittyVisualBell(value string) ([]setting, error) {
+	seconds, easing := cutField(value)
+	duration, err := strconv.ParseFloat(seconds, 64)
+	if err != nil || duration < 0 {
+		return nil, unrepresentable(value, "expected a duration in seconds")
+	}
+	if duration == 0 {
+		return nil, nil
+	}
+
+	approx := visualBellApprox
+	if easing != "" {
+		approx += ", without the " + easing + " animation"
+	}
+	border := bellFeature("border", true)
+	border.approx = approx
+	return []setting{border}, nil
+}
+
+// kitty's default bell_on_tab symbol
+const kittyBellSymbol = "🔔"
+
+// kittyBellOnTab converts bell_on_tab, a symbol shown in the tab title or
+// yes/no in older kitty versions
+func kittyBellOnTab(value string) ([]setting, error) {
+	symbol := strings.TrimSpace(value)
+	if enabled, ok := parseBool(symbol); ok {
+		return []setting{bellFeature("title", enabled)}, nil
+	}
+	switch symbol {
+	case "", "none":
+		return []setting{bellFeature("title", false)}, nil
+	case kittyBellSymbol:
+		return []setting{bellFeature("title", true)}, nil
+	}
+
+	title := bellFeature("title", true)
+	title.approx = "ghostty always marks the title with " + kittyBellSymbol + ", not " + symbol
+	return []setting{title}, nil
+}
+
+// alacrittyBellDuration converts bell.duration in milliseconds, zero turns the
+// visual bell off
+func alacrittyBellDuration(value string) ([]setting, error) {
+	duration, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || duration < 0 {
+		return nil, unrepresentable(value, "expected a duration in milliseconds")
+	}
+	if duration == 0 {
+		return nil, nil
+	}
+	border := bellFeature("border", true)
+	border.approx = visualBellApprox
+	return []setting{border}, nil
+}
+
+// mergeFlags combines every enabled key entry in config into the first one.
+// Ghostty starts each flags line from the defaults, so only one line counts.
+// Later settings of the same flag win.
+func mergeFlags(config *Document, key string) {
+	entries := config.Lookup(key)
+	if len(entries) < 2 {
+		return
+	}
+
+	var names []string
+	flags := make(map[string]string)
+	for _, entry := range entries {
+		for _, value := range entry.Values {
+			for _, flag := range strings.Split(value, ",") {
+				flag = strings.TrimSpace(flag)
+				name := strings.TrimPrefix(flag, "no-")
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+				flags[name] = flag
+			}
+		}
+	}
+
+	merged := make([]string, len(names))
+	for i, name := range names {
+		merged[i] = flags[name]
+	}
+	entries[0].Values = []string{strings.Join(merged, ",")}
+	config.Entries = slices.DeleteFunc(config.Entries, func(entry *Entry) bool {
+		return slices.Contains(entries[1:], entry)
+	})
+}
@@ -0,0 +1,149 @@
+package parser
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestMergeFlags(t *testing.T) {
+	tests := []struct {
+		name  string
+		lines []string
+		want  []string
+	}{
+		{"single line", []string{"bell-features = system"}, []string{"bell-features = system"}},
+		{"combined", []string{"bell-features = system", "bell-features = border"}, []string{"bell-features = system,border"}},
+		{"later wins", []string{"bell-features = no-title,system", "bell-features = title"}, []string{"bell-features = title,system"}},
+		{"negated later", []string{"bell-features = border", "bell-features = no-border"}, []string{"bell-features = no-border"}},
+		{
+			"other keys kept in place",
+			[]string{"bell-features = system", "font-size = 12", "bell-features = border"},
+			[]string{"bell-features = system,border", "font-size = 12"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDocument()
+			for _, line := range test.lines {
+				config.Append(ParseGhosttyConfig("test", line).Entries...)
+			}
+			mergeFlags(config, "bell-features")
+			if got := documentLines(config); !slices.Equal(got, test.want) {
+				t.Errorf("mergeFlags() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// reportStatus returns the status of the first report item for key
+func reportStatus(report *Report, key string) Status {
+	for _, item := range report.Items {
+		if item.Key == key {
+			return item.Status
+		}
+	}
+	return ""
+}
+
+func TestKittyBell(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   string
+		status map[string]Status
+	}{
+		{"audio bell off", "enable_audio_bell no\n", "no-system", nil},
+		{
+			"combined",
+			"enable_audio_bell no\nwindow_alert_on_bell yes\nvisual_bell_duration 0.1\nbell_on_tab none\n",
+			"no-system,attention,border,no-title",
+			map[string]Status{"visual_bell_duration": StatusApproximated, "bell_on_tab": StatusTransformed},
+		},
+		{"later setting wins", "enable_audio_bell no\nenable_audio_bell yes\n", "system", nil},
+		{"no visual bell", "visual_bell_duration 0\n", "", map[string]Status{"visual_bell_duration": StatusIgnoredAsDefault}},
+		{"other symbol", "bell_on_tab \"! \"\n", "title", map[string]Status{"bell_on_tab": StatusApproximated}},
+		{
+			"defaults",
+			"visual_bell_color none\ncommand_on_bell none\n",
+			"",
+			map[string]Status{"visual_bell_color": StatusIgnoredAsDefault, "command_on_bell": StatusIgnoredAsDefault},
+		},
+		{
+			"not defaults",
+			"visual_bell_color #ff0000\ncommand_on_bell notify-send bell\n",
+			"",
+			map[string]Status{"visual_bell_color": StatusUnsupported, "command_on_bell": StatusUnsupported},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDocument()
+			kittyParser := NewKittyParser("")
+			if err := kittyParser.parseLines(config, strings.NewReader(test.config), "kitty.conf", nil); err != nil {
+				t.Fatal(err)
+			}
+			converted, err := kittyParser.ConvertToGhostty(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(converted.GetAll("bell-features"), "\n"); got != test.want {
+				t.Errorf("bell-features = %q, want %q", got, test.want)
+			}
+			for key, want := range test.status {
+				if got := reportStatus(kittyParser.Report(), key); got != want {
+					t.Errorf("%s status = %s, want %s", key, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAlacrittyBell(t *testing.T) {
+	tests := []struct {
+		name   string
+		toml   string
+		want   string
+		status map[string]Status
+	}{
+		{"duration", "[bell]\nduration = 100\n", "border", map[string]Status{"bell.duration": StatusApproximated}},
+		{"no duration", "[bell]\nduration = 0\n", "", map[string]Status{"bell.duration": StatusIgnoredAsDefault}},
+		{
+			"defaults",
+			"[bell]\nanimation = \"Linear\"\ncolor = \"0xffffff\"\n",
+			"",
+			map[string]Status{"bell.animation": StatusIgnoredAsDefault, "bell.color": StatusIgnoredAsDefault},
+		},
+		{
+			"not defaults",
+			"[bell]\nanimation = \"EaseOut\"\ncolor = \"#ff0000\"\n",
+			"",
+			map[string]Status{"bell.animation": StatusUnsupported, "bell.color": StatusUnsupported},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeTOML("test.toml", []byte(test.toml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			config := NewDocument()
+			config.Tree = tree.Root
+			flattenTree(config, tree.Root, "", nil)
+
+			alacrittyParser := NewAlacrittyParser("")
+			converted, err := alacrittyParser.ConvertToGhostty(config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Join(converted.GetAll("bell-features"), "\n"); got != test.want {
+				t.Errorf("bell-features = %q, want %q", got, test.want)
+			}
+			for key, want := range test.status {
+				if got := reportStatus(alacrittyParser.Report(), key); got != want {
+					t.Errorf("%s status = %s, want %s", key, got, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package parser
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//go:embed x11_colors.txt
+var x11ColorsSource string
+
+// x11 color names to #rrggbb
+var x11Colors = parseX11Colors(x11ColorsSource)
+
+func parseX11Colors(source string) map[string]string {
+	colors := make(map[string]string)
+	for i, line := range strings.Split(source, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, hex, ok := strings.Cut(line, " ")
+		if !ok {
+			panic(fmt.Sprintf("x11 colors line %d: expected a name and a color", i+1))
+		}
+		colors[name] = hex
+	}
+	return colors
+}
+
+// normaliseColor converts a color to ghostty's #rrggbb. It understands hex
+// colors in the #rgb, #rrggbb, #rrrgggbbb, #rrrrggggbbbb and 0xrrggbb forms,
+// X11 rgb:r/g/b and rgbi:r/g/b specs and X11 color names.
+func normaliseColor(value string) (string, error) {
+	color := strings.ToLower(strings.TrimSpace(value))
+
+	switch {
+	case strings.HasPrefix(color, "#"):
+		return hexColor(value, color[1:])
+	case strings.HasPrefix(color, "0x"):
+		// alacritty only has the six digit form
+		if len(color) != 8 {
+			return "", unrepresentable(value, "expected 0xrrggbb")
+		}
+		return hexColor(value, color[2:])
+	case strings.HasPrefix(color, "rgb:"):
+		return rgbColor(value, color[4:], parseRGBComponent)
+	case strings.HasPrefix(color, "rgbi:"):
+		return rgbColor(value, color[5:], parseRGBIntensity)
+	}
+
+	if hex, ok := x11Colors[strings.ReplaceAll(color, " ", "")]; ok {
+		return hex, nil
+	}
+	return "", unrepresentable(value, "not a hex color or X11 color name")
+}
+
+// hexColor reads 3, 6, 9 or 12 hex digits, keeping the top byte of each
+// component
+func hexColor(value, hex string) (string, error) {
+	if len(hex) == 0 || len(hex)%3 != 0 || len(hex) > 12 {
+		return "", unrepresentable(value, "not a hex color")
+	}
+	if _, err := strconv.ParseUint(hex, 16, 64); err != nil {
+		return "", unrepresentable(value, "not a hex color")
+	}
+
+	width := len(hex) / 3
+	var rgb strings.Builder
+	rgb.WriteString("#")
+	for i := range 3 {
+		component := hex[i*width : (i+1)*width]
+		if width == 1 {
+			component += component
+		}
+		rgb.WriteString(component[:2])
+	}
+	return rgb.String(), nil
+}
+
+// rgbColor reads three / separated components with parse
+func rgbColor(value, spec string, parse func(string) (uint8, bool)) (string, error) {
+	components := strings.Split(spec, "/")
+	if len(components) != 3 {
+		return "", unrepresentable(value, "expected three / separated components")
+	}
+
+	var rgb [3]uint8
+	for i, component := range components {
+		parsed, ok := parse(component)
+		if !ok {
+			return "", unrepresentable(value, "invalid color component %q", component)
+		}
+		rgb[i] = parsed
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2]), nil
+}
+
+// rgb: components are one to four hex digits, scaled to a byte
+func parseRGBComponent(component string) (uint8, bool) {
+	if len(component) == 0 || len(component) > 4 {
+		return 0, false
+	}
+	parsed, err := strconv.ParseUint(component, 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	maximum := math.Pow(16, float64(len(component))) - 1
+	return uint8(math.Round(float64(parsed) / maximum * 255)), true
+}
+
+// rgbi: components are intensities between 0 and 1
+func parseRGBIntensity(component string) (uint8, bool) {
+	parsed, err := strconv.ParseFloat(component, 64)
+	if err != nil || parsed < 0 || parsed > 1 {
+		return 0, false
+	}
+	return uint8(math.Round(parsed * 255)), true
+}
+
+// ghostty's values for colors that follow the cell under the cursor or
+// selection
+const (
+	cellForeground = "cell-foreground"
+	cellBackground = "cell-background"
+)
+
+// specialColor converts values with a special meaning in the source terminal
+// to settings, other values are colors converted by transform
+func specialColor(specials map[string][]setting, transform valueTransform) valueTransform {
+	return func(value string) ([]setting, error) {
+		if settings, ok := specials[strings.ToLower(strings.TrimSpace(value))]; ok {
+			return settings, nil
+		}
+		return transform(value)
+	}
+}
@@ -0,0 +1,47 @@
+package parser
+
+import "testing"
+
+func TestNormaliseColor(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"#123456", "#123456"},
+		{"#ABCDEF", "#abcdef"},
+		{"#abc", "#aabbcc"},
+		{"#fff", "#ffffff"},
+		{"#111222333", "#112233"},
+		{"#ffff00000000", "#ff0000"},
+		{"0x1e1e2e", "#1e1e2e"},
+		{"rgb:ff/80/00", "#ff8000"},
+		{"rgb:f/8/0", "#ff8800"},
+		{"rgbi:1/0.5/0", "#ff8000"},
+		{"red", "#ff0000"},
+		{"White", "#ffffff"},
+		{"dark slate gray", "#2f4f4f"},
+		{"DarkSlateGray", "#2f4f4f"},
+		{" black ", "#000000"},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			got, err := normaliseColor(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("normaliseColor(%q) = %s, want %s", test.value, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormaliseColorErrors(t *testing.T) {
+	for _, value := range []string{"", "#", "#12", "#12345", "#ggg", "0x123", "rgb:1/2", "rgbi:2/0/0", "notacolor"} {
+		t.Run(value, func(t *testing.T) {
+			if got, err := normaliseColor(value); err == nil {
+				t.Errorf("normaliseColor(%q) = %s, want an error", value, got)
+			}
+		})
+	}
+}
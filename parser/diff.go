@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// lines of unchanged context around each change
+const diffContext = 3
+
+// an edit is one line of the diff, op is ' ', '-' or '+'
+type edit struct {
+	op   byte
+	line string
+}
+
+// UnifiedDiff returns the changes from before to after in unified diff
+// format, or "" when they are the same
+func UnifiedDiff(beforeName, afterName, before, after string) string {
+	edits := diffLines(splitLines(before), splitLines(after))
+	if !hasChanges(edits) {
+		return ""
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", beforeName, afterName))
+
+	// line numbers of the current edit in before and after
+	beforeLine, afterLine := 1, 1
+	for start := 0; start < len(edits); {
+		// skip to the next change
+		if edits[start].op == ' ' {
+			beforeLine++
+			afterLine++
+			start++
+			continue
+		}
+
+		// a hunk starts with up to diffContext lines of context
+		hunkStart := max(start-diffContext, 0)
+		for i := hunkStart; i < start; i++ {
+			beforeLine--
+			afterLine--
+		}
+
+		// and runs until there are more than two contexts worth of unchanged lines
+		end := start
+		for end < len(edits) {
+			if edits[end].op != ' ' {
+				end++
+				continue
+			}
+			unchanged := 0
+			for end+unchanged < len(edits) && edits[end+unchanged].op == ' ' {
+				unchanged++
+			}
+			if end+unchanged == len(edits) || unchanged > 2*diffContext {
+				end += min(unchanged, diffContext)
+				break
+			}
+			end += unchanged
+		}
+
+		beforeCount, afterCount := 0, 0
+		for _, e := range edits[hunkStart:end] {
+			if e.op != '+' {
+				beforeCount++
+			}
+			if e.op != '-' {
+				afterCount++
+			}
+		}
+		builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n",
+			hunkRange(beforeLine, beforeCount), hunkRange(afterLine, afterCount)))
+		for _, e := range edits[hunkStart:end] {
+			builder.WriteString(string(e.op) + e.line + "\n")
+		}
+
+		beforeLine += beforeCount
+		afterLine += afterCount
+		start = end
+	}
+
+	return builder.String()
+}
+
+// hunkRange formats a start,count pair, an empty range points at the line
+// before it like diff -u does
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start-1)
+	case 1:
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func hasChanges(edits []edit) bool {
+	for _, e := range edits {
+		if e.op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// diffLines finds the edits from before to after using the longest common
+// subsequence of lines, configs are small enough for the quadratic table
+func diffLines(before, after []string) []edit {
+	// common[i][j] is the length of the lcs of before[i:] and after[j:]
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]edit, 0, len(before)+len(after))
+	i, j := 0, 0
+	for i < len(before) && j < len(after) {
+		switch {
+		case before[i] == after[j]:
+			edits = append(edits, edit{' ', before[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			edits = append(edits, edit{'-', before[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', after[j]})
+			j++
+		}
+	}
+	for ; i < len(before); i++ {
+		edits = append(edits, edit{'-', before[i]})
+	}
+	for ; j < len(after); j++ {
+		edits = append(edits, edit{'+', after[j]})
+	}
+	return edits
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// n distinct lines, a, b, c...
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = string(rune('a'+i%26)) + strings.Repeat("x", i/26)
+	}
+	return lines
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	long := numberedLines(20)
+	changed := append([]string{}, long...)
+	changed[1] = "B"
+	changed[17] = "R"
+
+	tests := []struct {
+		name          string
+		before, after string
+		want          string
+	}{
+		{"same", "a\nb\n", "a\nb\n", ""},
+		{
+			"changed line",
+			"a\nb\nc\n", "a\nB\nc\n",
+			"--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			"added to an empty file",
+			"", "a\nb\n",
+			"--- old\n+++ new\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"removed everything",
+			"a\n", "",
+			"--- old\n+++ new\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			"appended",
+			"a\nb\nc\nd\ne\n", "a\nb\nc\nd\ne\nf\n",
+			"--- old\n+++ new\n@@ -3,3 +3,4 @@\n c\n d\n e\n+f\n",
+		},
+		{
+			"separate hunks",
+			strings.Join(long, "\n") + "\n", strings.Join(changed, "\n") + "\n",
+			"--- old\n+++ new\n" +
+				"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+				"@@ -15,6 +15,6 @@\n o\n p\n q\n-r\n+R\n s\n t\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := UnifiedDiff("old", "new", test.before, test.after); got != test.want {
+				t.Errorf("UnifiedDiff() =\n%s\nwant\n%s", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package parser
+
+// Entry is a single setting in a config document. Values holds every value
+// attached to the key at this position, so repeatable keys (keybind, palette,
+// font-family fallbacks) are never collapsed into one.
+type Entry struct {
+	Key    string
+	Values []string
+
+	// where the entry came from, used for warnings and reports
+	Source string
+	Line   int
+
+	// comment lines (without the leading #) written above the entry
+	Comments []string
+
+	// disabled entries are written commented out, eg unmapped settings
+	Disabled bool
+}
+
+// Value returns the effective value of the entry, later values win
+func (e *Entry) Value() string {
+	if len(e.Values) == 0 {
+		return ""
+	}
+	return e.Values[len(e.Values)-1]
+}
+
+// Document is an ordered list of entries shared by every parser and writer
+type Document struct {
+	Entries []*Entry
+
+	// Tree is the nested source for formats built from tables (alacritty),
+	// entries hold the same settings flattened to dotted keys
+	Tree map[string]any
+}
+
+// constructor for an empty document
+func NewDocument() *Document {
+	return &Document{}
+}
+
+// Add appends a new entry with a single value and returns it
+func (d *Document) Add(key, value, source string, line int) *Entry {
+	entry := &Entry{Key: key, Values: []string{value}, Source: source, Line: line}
+	d.Entries = append(d.Entries, entry)
+	return entry
+}
+
+// Append adds existing entries to the end of the document
+func (d *Document) Append(entries ...*Entry) {
+	d.Entries = append(d.Entries, entries...)
+}
+
+// Lookup returns every enabled entry for key in document order
+func (d *Document) Lookup(key string) []*Entry {
+	var entries []*Entry
+	for _, entry := range d.Entries {
+		if entry.Key == key && !entry.Disabled {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Get returns the effective value for key, later entries override earlier ones
+func (d *Document) Get(key string) (string, bool) {
+	entries := d.Lookup(key)
+	if len(entries) == 0 {
+		return "", false
+	}
+	return entries[len(entries)-1].Value(), true
+}
+
+// GetAll returns every value for a repeatable key in order
+func (d *Document) GetAll(key string) []string {
+	var values []string
+	for _, entry := range d.Lookup(key) {
+		values = append(values, entry.Values...)
+	}
+	return values
+}
+
+// Set replaces all values for key with a single value, keeping the position
+// of the first occurrence or appending when the key is new
+func (d *Document) Set(key, value string) *Entry {
+	var kept *Entry
+	entries := d.Entries[:0]
+	for _, entry := range d.Entries {
+		if entry.Key == key && !entry.Disabled {
+			if kept != nil {
+				continue
+			}
+			kept = entry
+			entry.Values = []string{value}
+		}
+		entries = append(entries, entry)
+	}
+	d.Entries = entries
+
+	if kept == nil {
+		kept = d.Add(key, value, "", 0)
+	}
+	return kept
+}
+
+// Len returns the number of entries
+func (d *Document) Len() int {
+	return len(d.Entries)
+}
@@ -0,0 +1,39 @@
+package parser
+
+import (
+	"strings"
+)
+
+// ParseGhosttyConfig reads an existing ghostty config. Comments and blank
+// lines are attached to the entry that follows them, so the document keeps
+// the layout of the file.
+func ParseGhosttyConfig(source, contents string) *Document {
+	config := NewDocument()
+
+	var comments []string
+	for i, line := range splitLines(contents) {
+		trimmed := strings.TrimSpace(line)
+
+		// ghostty only supports whole line comments
+		if trimmed == "" {
+			comments = append(comments, "")
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(trimmed, "#")))
+			continue
+		}
+
+		// a key without a value resets it to the default
+		key, value, _ := strings.Cut(trimmed, "=")
+		entry := config.Add(strings.TrimSpace(key), strings.TrimSpace(value), source, i+1)
+		entry.Comments = comments
+		comments = nil
+	}
+
+	// keep trailing comments on an entry without a key
+	if len(comments) > 0 {
+		config.Append(&Entry{Comments: comments, Source: source})
+	}
+	return config
+}
@@ -0,0 +1,126 @@
+package parser
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ghostty names for the keys that can't be written as themselves in a
+// trigger, = and + are part of the keybind syntax
+var punctuationKeyNames = map[string]string{
+	"`":  "grave_accent",
+	"-":  "minus",
+	"=":  "equal",
+	"+":  "plus",
+	",":  "comma",
+	".":  "period",
+	"/":  "slash",
+	"\\": "backslash",
+	";":  "semicolon",
+	"'":  "apostrophe",
+	"[":  "left_bracket",
+	"]":  "right_bracket",
+	" ":  "space",
+}
+
+// named keys ghostty accepts in triggers
+var ghosttyKeyNames = map[string]bool{
+	"enter": true, "escape": true, "tab": true, "backspace": true, "insert": true,
+	"delete": true, "home": true, "end": true, "page_up": true, "page_down": true,
+	"up": true, "down": true, "left": true, "right": true, "space": true,
+	"plus": true, "minus": true, "equal": true, "comma": true, "period": true,
+	"slash": true, "backslash": true, "semicolon": true, "apostrophe": true,
+	"grave_accent": true, "left_bracket": true, "right_bracket": true,
+	"print_screen": true, "pause": true, "scroll_lock": true, "caps_lock": true,
+	"num_lock": true, "kp_decimal": true, "kp_divide": true, "kp_multiply": true,
+	"kp_subtract": true, "kp_add": true, "kp_enter": true, "kp_equal": true,
+}
+
+// ghosttyKeyName returns the ghostty name for a lowercase key that is either
+// already a ghostty key name or a single character
+func ghosttyKeyName(key string) (string, bool) {
+	if name, ok := punctuationKeyNames[key]; ok {
+		return name, true
+	}
+	if ghosttyKeyNames[key] {
+		return key, true
+	}
+
+	// function keys and keypad digits
+	if number, ok := strings.CutPrefix(key, "f"); ok {
+		if n, err := strconv.Atoi(number); err == nil && n >= 1 && n <= 25 {
+			return key, true
+		}
+	}
+	if digit, ok := strings.CutPrefix(key, "kp_"); ok && len(digit) == 1 && digit[0] >= '0' && digit[0] <= '9' {
+		return key, true
+	}
+
+	// any other single character is bound by its codepoint
+	if utf8.RuneCountInString(key) == 1 {
+		return key, true
+	}
+	return "", false
+}
+
+// textAction is the ghostty action that sends payload to the terminal,
+// written with the escapes ghostty's text: parser understands
+func textAction(payload string) string {
+	var builder strings.Builder
+	builder.WriteString("text:")
+	for i := 0; i < len(payload); {
+		r, size := utf8.DecodeRuneInString(payload[i:])
+		switch {
+		case r == '\\':
+			builder.WriteString(`\\`)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == ' ' && (i == 0 || i == len(payload)-1):
+			// the config parser trims surrounding spaces
+			builder.WriteString(`\x20`)
+		case r == utf8.RuneError && size == 1, r < 0x20, r == 0x7f:
+			// invalid utf-8 and control characters are written as bytes
+			builder.WriteString(`\x` + leftPad(strconv.FormatUint(uint64(payload[i]), 16), 2))
+		default:
+			builder.WriteRune(r)
+		}
+		i += size
+	}
+	return builder.String()
+}
+
+func leftPad(text string, width int) string {
+	return strings.Repeat("0", max(width-len(text), 0)) + text
+}
+
+// sendAction picks the ghostty action that sends payload to the terminal:
+// csi: and esc: when the payload is a single escape sequence with a printable
+// body, text: with escapes for anything else
+func sendAction(payload string) string {
+	if body, ok := strings.CutPrefix(payload, "\x1b["); ok && isPlainSequence(body) {
+		return "csi:" + body
+	}
+	if body, ok := strings.CutPrefix(payload, "\x1b"); ok && isPlainSequence(body) {
+		return "esc:" + body
+	}
+	return textAction(payload)
+}
+
+// csi: and esc: are written as is, so the body has to be printable ascii
+// that survives the config parser trimming spaces
+func isPlainSequence(body string) bool {
+	if body == "" || body[0] == ' ' || body[len(body)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(body); i++ {
+		if body[i] < 0x20 || body[i] >= 0x7f {
+			return false
+		}
+	}
+	return true
+}
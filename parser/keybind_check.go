@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"ghostty-ghost/ghostty"
+	"slices"
+	"strings"
+)
+
+// a keybind in effect while checking, from the config or ghostty's defaults
+type boundKey struct {
+	trigger string
+	action  string
+	entry   *Entry
+}
+
+func (b boundKey) origin() string {
+	if b.entry == nil {
+		return "ghostty's defaults"
+	}
+	if b.entry.Line == 0 {
+		return b.entry.Source
+	}
+	return fmt.Sprintf("%s:%d", b.entry.Source, b.entry.Line)
+}
+
+// CheckKeybinds looks for keybinds in config that collide with each other or
+// with ghostty's default keybinds on platform: triggers bound twice, sequences
+// that can't be reached because a prefix is bound, and defaults replaced
+func CheckKeybinds(config *Document, platform string) []Problem {
+	var problems []Problem
+	report := func(entry *Entry, format string, args ...any) {
+		problems = append(problems, Problem{
+			Source:   entry.Source,
+			Line:     entry.Line,
+			Key:      entry.Key,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	defaults := make(map[string]boundKey)
+	for _, keybind := range ghostty.DefaultKeybinds(platform) {
+		trigger := ghostty.NormalizeTrigger(keybind.Trigger)
+		defaults[trigger] = boundKey{keybind.Trigger, keybind.Action, nil}
+	}
+	bound := make(map[string]boundKey)
+
+	// the binding for trigger in effect, the config's or the default
+	lookup := func(trigger string) (boundKey, bool) {
+		if binding, ok := bound[trigger]; ok {
+			return binding, binding.action != "unbind"
+		}
+		binding, ok := defaults[trigger]
+		return binding, ok
+	}
+
+	for _, entry := range config.Lookup("keybind") {
+		for _, value := range entry.Values {
+			value = unquoteGhostty(value)
+			if value == "clear" {
+				// drops the defaults and everything bound so far
+				clear(defaults)
+				clear(bound)
+				continue
+			}
+
+			trigger, action, ok := ghostty.SplitKeybind(value)
+			if !ok {
+				continue
+			}
+			normalised := ghostty.NormalizeTrigger(trigger)
+			current := boundKey{trigger, action, entry}
+
+			if previous, ok := bound[normalised]; ok {
+				if previous.action == action {
+					report(entry, "%s is already bound to %s at %s", trigger, action, previous.origin())
+				} else {
+					report(entry, "%s is already bound to %s at %s, %s replaces it", trigger, previous.action, previous.origin(), action)
+				}
+			} else if binding, ok := defaults[normalised]; ok {
+				switch action {
+				case binding.action:
+					report(entry, "%s=%s is already one of ghostty's default keybinds", trigger, action)
+				case "unbind":
+					// removing a default on purpose
+				default:
+					report(entry, "%s shadows ghostty's default keybind for %s", trigger, binding.action)
+				}
+			} else if action == "unbind" {
+				report(entry, "%s is not bound, unbind has no effect", trigger)
+			}
+
+			if action != "unbind" {
+				problems = append(problems, sequenceProblems(entry, trigger, normalised, lookup, bound, defaults)...)
+			}
+			bound[normalised] = current
+		}
+	}
+	return problems
+}
+
+// sequenceProblems finds bindings that make trigger or another sequence
+// unreachable: a bound prefix of trigger, or bound sequences that trigger is
+// a prefix of
+func sequenceProblems(entry *Entry, trigger, normalised string, lookup func(string) (boundKey, bool), maps ...map[string]boundKey) []Problem {
+	var problems []Problem
+	add := func(format string, args ...any) {
+		problems = append(problems, Problem{entry.Source, entry.Line, entry.Key, SeverityWarning, fmt.Sprintf(format, args...)})
+	}
+
+	steps := strings.Split(normalised, ">")
+	for i := 1; i < len(steps); i++ {
+		prefix := strings.Join(steps[:i], ">")
+		if binding, ok := lookup(prefix); ok {
+			add("sequence %s can't be reached, %s is bound to %s at %s", trigger, binding.trigger, binding.action, binding.origin())
+			return problems
+		}
+	}
+
+	var shadowed []string
+	for _, bindings := range maps {
+		for other, binding := range bindings {
+			if !strings.HasPrefix(other, normalised+">") || binding.action == "unbind" {
+				continue
+			}
+			if current, ok := lookup(other); ok && current == binding {
+				shadowed = append(shadowed, fmt.Sprintf("%s (%s)", binding.trigger, binding.origin()))
+			}
+		}
+	}
+	if len(shadowed) > 0 {
+		slices.Sort(shadowed)
+		add("%s makes the sequences %s unreachable", trigger, strings.Join(shadowed, ", "))
+	}
+	return problems
+}
+
+// LoadGhosttyConfig reads the ghostty config at path followed by the files it
+// loads with config-file, in the order ghostty applies them. Files that can't
+// be loaded are skipped like ghostty does, ValidateGhosttyFile reports them.
+func LoadGhosttyConfig(path string) (*Document, error) {
+	files, err := loadGhosttyFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := NewDocument()
+	for _, file := range files {
+		config.Append(file.config.Entries...)
+	}
+	return config, nil
+}
@@ -0,0 +1,173 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCheckKeybinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		messages []string
+	}{
+		{"no conflicts", "keybind = ctrl+a>n=next_tab\nkeybind = alt+x=new_tab\n", nil},
+		{"bound twice", "keybind = alt+x=new_tab\nkeybind = alt+x=close_tab\n",
+			[]string{"alt+x is already bound to new_tab at config:1, close_tab replaces it"}},
+		{"bound twice the same", "keybind = alt+x=new_tab\nkeybind = alt+x=new_tab\n",
+			[]string{"alt+x is already bound to new_tab at config:1"}},
+		{"same trigger spelt differently", "keybind = alt+shift+x=new_tab\nkeybind = shift+alt+x=close_tab\n",
+			[]string{"shift+alt+x is already bound to new_tab at config:1, close_tab replaces it"}},
+		{"default repeated", "keybind = ctrl+shift+c=copy_to_clipboard\n",
+			[]string{"ctrl+shift+c=copy_to_clipboard is already one of ghostty's default keybinds"}},
+		{"default shadowed", "keybind = ctrl+shift+c=new_tab\n",
+			[]string{"ctrl+shift+c shadows ghostty's default keybind for copy_to_clipboard"}},
+		{"default unbound", "keybind = ctrl+shift+c=unbind\n", nil},
+		{"unbind without a binding", "keybind = alt+x=unbind\n",
+			[]string{"alt+x is not bound, unbind has no effect"}},
+		{"prefix bound first", "keybind = alt+a=new_tab\nkeybind = alt+a>n=next_tab\n",
+			[]string{"sequence alt+a>n can't be reached, alt+a is bound to new_tab at config:1"}},
+		{"prefix bound later", "keybind = alt+a>n=next_tab\nkeybind = alt+a>p=previous_tab\nkeybind = alt+a=new_tab\n",
+			[]string{"alt+a makes the sequences alt+a>n (config:1), alt+a>p (config:2) unreachable"}},
+		{"prefix is a default", "keybind = ctrl+shift+c>n=next_tab\n",
+			[]string{"sequence ctrl+shift+c>n can't be reached, ctrl+shift+c is bound to copy_to_clipboard at ghostty's defaults"}},
+		{"unbound prefix", "keybind = ctrl+shift+c=unbind\nkeybind = ctrl+shift+c>n=next_tab\n", nil},
+		{"clear drops the defaults", "keybind = clear\nkeybind = ctrl+shift+c=new_tab\n", nil},
+		{"clear drops earlier bindings", "keybind = alt+x=new_tab\nkeybind = clear\nkeybind = alt+x=close_tab\n", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var messages []string
+			for _, problem := range CheckKeybinds(ParseGhosttyConfig("config", test.config), "linux") {
+				messages = append(messages, problem.Message)
+			}
+			if !slices.Equal(messages, test.messages) {
+				t.Errorf("CheckKeybinds() =\n%s\nwant\n%s", strings.Join(messages, "\n"), strings.Join(test.messages, "\n"))
+			}
+		})
+	}
+}
+
+// write files into a temporary directory, returning its path
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, contents := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadGhosttyFiles(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    map[string]string
+		order    []string
+		problems []string
+	}{
+		{
+			"includes after the file",
+			map[string]string{
+				"config": "config-file = a\nfont-size = 12\nconfig-file = b\n",
+				"a":      "config-file = sub/c\n",
+				"b":      "",
+				"sub/c":  "",
+			},
+			[]string{"config", "a", "sub/c", "b"},
+			nil,
+		},
+		{
+			"relative to the including file",
+			map[string]string{"config": "config-file = sub/a\n", "sub/a": "config-file = b\n", "sub/b": ""},
+			[]string{"config", "sub/a", "sub/b"},
+			nil,
+		},
+		{
+			"quoted path",
+			map[string]string{"config": "config-file = \"a\"\n", "a": ""},
+			[]string{"config", "a"},
+			nil,
+		},
+		{
+			"optional missing",
+			map[string]string{"config": "config-file = ?missing\n"},
+			[]string{"config"},
+			nil,
+		},
+		{
+			"required missing",
+			map[string]string{"config": "config-file = missing\n"},
+			[]string{"config"},
+			[]string{"config-file missing does not exist"},
+		},
+		{
+			"cycle",
+			map[string]string{"config": "config-file = a\n", "a": "config-file = config\n"},
+			[]string{"config", "a"},
+			[]string{"config-file config includes itself"},
+		},
+		{
+			"same file twice",
+			map[string]string{"config": "config-file = a\nconfig-file = a\n", "a": ""},
+			[]string{"config", "a", "a"},
+			nil,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dir := writeFiles(t, test.files)
+			files, err := loadGhosttyFiles(filepath.Join(dir, "config"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var order, problems []string
+			for _, file := range files {
+				name, err := filepath.Rel(dir, file.path)
+				if err != nil {
+					t.Fatal(err)
+				}
+				order = append(order, filepath.ToSlash(name))
+				for _, problem := range file.problems {
+					problems = append(problems, problem.Message)
+				}
+			}
+			if !slices.Equal(order, test.order) {
+				t.Errorf("files = %q, want %q", order, test.order)
+			}
+			if !slices.Equal(problems, test.problems) {
+				t.Errorf("problems = %q, want %q", problems, test.problems)
+			}
+		})
+	}
+}
+
+// later files override earlier ones, so the keybinds of an included file win
+func TestLoadGhosttyConfigOrder(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"config": "keybind = alt+x=new_tab\nconfig-file = a\nkeybind = alt+y=new_tab\n",
+		"a":      "keybind = alt+x=close_tab\n",
+	})
+	config, err := LoadGhosttyConfig(filepath.Join(dir, "config"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"alt+x=new_tab", "alt+y=new_tab", "alt+x=close_tab"}
+	if got := config.GetAll("keybind"); !slices.Equal(got, want) {
+		t.Errorf("keybinds = %q, want %q", got, want)
+	}
+
+	problems := CheckKeybinds(config, "linux")
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "close_tab replaces it") {
+		t.Errorf("CheckKeybinds() = %v, want close_tab to replace new_tab", problems)
+	}
+}
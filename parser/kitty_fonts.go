@@ -0,0 +1,439 @@
+package parser
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// the highest unicode codepoint
+const maxCodepoint = 0x10ffff
+
+// an inclusive range of codepoints
+type codepointRange struct {
+	first, last int
+}
+
+func (r codepointRange) String() string {
+	if r.first == r.last {
+		return fmt.Sprintf("U+%04X", r.first)
+	}
+	return fmt.Sprintf("U+%04X-U+%04X", r.first, r.last)
+}
+
+// parseCodepoint reads a single U+XXXX codepoint
+func parseCodepoint(text string) (int, error) {
+	hex, ok := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(text)), "U+")
+	if !ok {
+		return 0, fmt.Errorf("%q is not a U+XXXX codepoint", text)
+	}
+	codepoint, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil || codepoint > maxCodepoint {
+		return 0, fmt.Errorf("%q is not a unicode codepoint", text)
+	}
+	return int(codepoint), nil
+}
+
+// parseCodepointRanges reads kitty's comma separated list of codepoints and
+// ranges, eg U+E0A0-U+E0A3,U+E0C0. Overlapping and adjacent ranges are merged.
+func parseCodepointRanges(list string) ([]codepointRange, error) {
+	var ranges []codepointRange
+	for _, part := range strings.Split(list, ",") {
+		firstText, lastText, isRange := strings.Cut(part, "-")
+		first, err := parseCodepoint(firstText)
+		if err != nil {
+			return nil, err
+		}
+		last := first
+		if isRange {
+			if last, err = parseCodepoint(lastText); err != nil {
+				return nil, err
+			}
+		}
+		if last < first {
+			return nil, fmt.Errorf("range %s ends before it starts", strings.TrimSpace(part))
+		}
+		ranges = append(ranges, codepointRange{first, last})
+	}
+	return mergeCodepointRanges(ranges), nil
+}
+
+// mergeCodepointRanges sorts ranges, merging overlapping and adjacent ones
+func mergeCodepointRanges(ranges []codepointRange) []codepointRange {
+	if len(ranges) == 0 {
+		return ranges
+	}
+	slices.SortFunc(ranges, func(a, b codepointRange) int { return a.first - b.first })
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		previous := &merged[len(merged)-1]
+		if r.first <= previous.last+1 {
+			previous.last = max(previous.last, r.last)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// without removes the codepoints of other from r, leaving up to two ranges
+func (r codepointRange) without(other codepointRange) []codepointRange {
+	if other.last < r.first || other.first > r.last {
+		return []codepointRange{r}
+	}
+	var rest []codepointRange
+	if other.first > r.first {
+		rest = append(rest, codepointRange{r.first, other.first - 1})
+	}
+	if other.last < r.last {
+		rest = append(rest, codepointRange{other.last + 1, r.last})
+	}
+	return rest
+}
+
+func joinCodepointRanges(ranges []codepointRange) string {
+	parts := make([]string, len(ranges))
+	for i, r := range ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// kittySymbolMap converts symbol_map <codepoints> <font family> to a
+// font-codepoint-map entry
+func kittySymbolMap(value string) ([]setting, error) {
+	list, family, _ := strings.Cut(strings.TrimSpace(value), " ")
+	family = strings.TrimSpace(family)
+	if family == "" {
+		return nil, unrepresentable(value, "expected codepoints followed by a font family")
+	}
+
+	ranges, err := parseCodepointRanges(list)
+	if err != nil {
+		return nil, unrepresentable(value, "%v", err)
+	}
+	return []setting{{key: "font-codepoint-map", value: joinCodepointRanges(ranges) + "=" + family}}, nil
+}
+
+// mergeCodepointMaps combines the font-codepoint-map entries in config into
+// one per font family, in place of its first entry. Kitty and ghostty both
+// let later maps win, so codepoints mapped again only stay with the later
+// family.
+func mergeCodepointMaps(config *Document) {
+	entries := config.Lookup("font-codepoint-map")
+	if len(entries) < 2 {
+		return
+	}
+
+	type mapping struct {
+		codepoints codepointRange
+		family     string
+	}
+	var mappings []mapping
+	var families []string
+	for _, entry := range entries {
+		for _, value := range entry.Values {
+			list, family, ok := strings.Cut(value, "=")
+			ranges, err := parseCodepointRanges(list)
+			if !ok || err != nil {
+				// not a value kittySymbolMap made, leave the entries alone
+				return
+			}
+			if !slices.Contains(families, family) {
+				families = append(families, family)
+			}
+			for _, r := range ranges {
+				var kept []mapping
+				for _, m := range mappings {
+					for _, rest := range m.codepoints.without(r) {
+						kept = append(kept, mapping{rest, m.family})
+					}
+				}
+				mappings = append(kept, mapping{r, family})
+			}
+		}
+	}
+
+	merged := make(map[string]string)
+	for _, family := range families {
+		var ranges []codepointRange
+		for _, m := range mappings {
+			if m.family == family {
+				ranges = append(ranges, m.codepoints)
+			}
+		}
+		if len(ranges) > 0 {
+			merged[family] = joinCodepointRanges(mergeCodepointRanges(ranges)) + "=" + family
+		}
+	}
+
+	for _, entry := range entries {
+		var values []string
+		for _, value := range entry.Values {
+			_, family, _ := strings.Cut(value, "=")
+			if mergedValue, ok := merged[family]; ok {
+				values = append(values, mergedValue)
+				delete(merged, family)
+			}
+		}
+		entry.Values = values
+	}
+	config.Entries = slices.DeleteFunc(config.Entries, func(entry *Entry) bool {
+		return entry.Key == "font-codepoint-map" && !entry.Disabled && len(entry.Values) == 0
+	})
+}
+
+// kittyNarrowSymbols reports narrow_symbols <codepoints> [cells], ghostty
+// decides the width of symbols itself
+func kittyNarrowSymbols(value string) ([]setting, error) {
+	list, width, _ := strings.Cut(strings.TrimSpace(value), " ")
+	ranges, err := parseCodepointRanges(list)
+	if err != nil {
+		return nil, unrepresentable(value, "%v", err)
+	}
+
+	cells := 1
+	if width = strings.TrimSpace(width); width != "" {
+		if cells, err = strconv.Atoi(width); err != nil || cells < 1 {
+			return nil, unrepresentable(value, "expected a width in cells")
+		}
+	}
+	unit := "cells"
+	if cells == 1 {
+		unit = "cell"
+	}
+	return nil, unrepresentable(value, "ghostty can't force %s to be %d %s wide, it picks the width of symbols itself",
+		joinCodepointRanges(ranges), cells, unit)
+}
+
+// splitQuoted splits text on whitespace like a shell, quotes group words and
+// are removed, eg family="JetBrains Mono" style=Bold
+func splitQuoted(text string) ([]string, error) {
+	var fields []string
+	var field strings.Builder
+	inField := false
+	var quote rune
+	for _, c := range text {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				field.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inField = true
+		case c == ' ' || c == '\t':
+			if inField {
+				fields = append(fields, field.String())
+				field.Reset()
+				inField = false
+			}
+		default:
+			field.WriteRune(c)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inField {
+		fields = append(fields, field.String())
+	}
+	return fields, nil
+}
+
+// the named fields of a kitty font spec, any other name is a variable font axis
+var kittyFontSpecFields = []string{"family", "style", "postscript_name", "full_name", "features", "variable_name"}
+
+// kittyFont converts a font_family, bold_font, italic_font or bold_italic_font
+// value. Plain names are the family, specs such as
+// family="JetBrains Mono" style=Medium features="+zero" wght=500 are split
+// over the ghostty family, style, feature and variation keys. suffix is the
+// ghostty key suffix for the style, eg -bold.
+func kittyFont(suffix string) valueTransform {
+	return func(value string) ([]setting, error) {
+		fields, err := splitQuoted(value)
+		if err != nil {
+			return nil, unrepresentable(value, "%v", err)
+		}
+		if len(fields) == 0 || !strings.Contains(fields[0], "=") {
+			return []setting{{key: "font-family" + suffix, value: strings.TrimSpace(value)}}, nil
+		}
+
+		spec := make(map[string]string)
+		var settings []setting
+		for _, field := range fields {
+			name, fieldValue, ok := strings.Cut(field, "=")
+			if !ok || name == "" {
+				return nil, unrepresentable(value, "expected name=value, got %q", field)
+			}
+			if slices.Contains(kittyFontSpecFields, name) {
+				spec[name] = fieldValue
+				continue
+			}
+			// variable font axes, eg wght=500
+			if _, err := strconv.ParseFloat(fieldValue, 64); err != nil {
+				return nil, unrepresentable(value, "expected a number for the %s axis", name)
+			}
+			settings = append(settings, setting{key: "font-variation" + suffix, value: name + "=" + fieldValue})
+		}
+
+		var approx []string
+		family := spec["family"]
+		if family == "" {
+			// ghostty looks fonts up by family, the full and postscript
+			// names usually match one too
+			family = cmp.Or(spec["full_name"], spec["postscript_name"])
+			if family != "" {
+				approx = append(approx, "ghostty looks fonts up by family name, "+family+" may not match one")
+			}
+		}
+		if spec["variable_name"] != "" {
+			approx = append(approx, "ghostty has no named variable font instances, set the axes instead")
+		}
+
+		var converted []setting
+		if family != "" {
+			converted = append(converted, setting{key: "font-family" + suffix, value: family})
+		}
+		if spec["style"] != "" {
+			converted = append(converted, setting{key: "font-style" + suffix, value: spec["style"]})
+		}
+		converted = append(converted, settings...)
+
+		features := strings.Fields(spec["features"])
+		if suffix != "" && len(features) > 0 {
+			approx = append(approx, "ghostty applies font features to every style")
+		}
+		for _, feature := range features {
+			converted = append(converted, setting{key: "font-feature", value: feature})
+		}
+
+		if len(converted) == 0 {
+			return nil, unrepresentable(value, "the font spec has nothing ghostty can use")
+		}
+		for i := range converted {
+			converted[i].approx = strings.Join(approx, "; ")
+		}
+		return converted, nil
+	}
+}
+
+// kittyFontFeatures converts font_features <postscript name> <features...>,
+// ghostty features apply to every font
+func kittyFontFeatures(value string) ([]setting, error) {
+	font, rest := cutField(value)
+	if font == "none" {
+		return nil, nil
+	}
+	features := strings.Fields(rest)
+	if len(features) == 0 || features[0] == "none" {
+		return nil, nil
+	}
+
+	approx := fmt.Sprintf("ghostty applies font features to every font, not just %s", font)
+	settings := make([]setting, len(features))
+	for i, feature := range features {
+		settings[i] = setting{key: "font-feature", value: feature, approx: approx}
+	}
+	return settings, nil
+}
+
+// features that turn off ligatures in ghostty
+var ligatureFeatures = []string{"-calt", "-liga", "-dlig"}
+
+// kittyDisableLigatures converts disable_ligatures. Ghostty splits ligatures
+// under the cursor unless told otherwise, kitty doesn't.
+func kittyDisableLigatures(value string) ([]setting, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "never":
+		return []setting{{key: "font-shaping-break", value: "no-cursor"}}, nil
+	case "cursor":
+		return []setting{{key: "font-shaping-break", value: "cursor"}}, nil
+	case "always":
+		settings := make([]setting, len(ligatureFeatures))
+		for i, feature := range ligatureFeatures {
+			settings[i] = setting{key: "font-feature", value: feature}
+		}
+		return settings, nil
+	}
+	return nil, unrepresentable(value, "expected never, cursor or always")
+}
+
+// dropRepeatedValues removes values of a repeatable key that are set again
+// later, eg a -calt from both font_features and disable_ligatures, keeping
+// the last so its order against other values is unchanged
+func dropRepeatedValues(config *Document, key string) {
+	entries := config.Lookup(key)
+	later := make(map[string]bool)
+	for i := len(entries) - 1; i >= 0; i-- {
+		var values []string
+		for j := len(entries[i].Values) - 1; j >= 0; j-- {
+			value := entries[i].Values[j]
+			if !later[value] {
+				values = append(values, value)
+			}
+			later[value] = true
+		}
+		slices.Reverse(values)
+		entries[i].Values = values
+	}
+	config.Entries = slices.DeleteFunc(config.Entries, func(entry *Entry) bool {
+		return entry.Key == key && !entry.Disabled && len(entry.Values) == 0
+	})
+}
+
+// modify_font fields and the ghostty metric they adjust
+var kittyFontMetrics = map[string]string{
+	"cell_width":              "adjust-cell-width",
+	"cell_height":             "adjust-cell-height",
+	"baseline":                "adjust-font-baseline",
+	"underline_position":      "adjust-underline-position",
+	"underline_thickness":     "adjust-underline-thickness",
+	"strikethrough_position":  "adjust-strikethrough-position",
+	"strikethrough_thickness": "adjust-strikethrough-thickness",
+}
+
+// kittyModifyFont converts modify_font <field> <amount>. Kitty amounts are in
+// points, pixels with px or a percentage of the original with %, ghostty takes
+// pixels or a percentage change, so 110% becomes 10%.
+func kittyModifyFont(value string) ([]setting, error) {
+	field, amount := cutField(value)
+	key, ok := kittyFontMetrics[field]
+	if !ok {
+		return nil, unrepresentable(value, "ghostty can't adjust the font %s", field)
+	}
+
+	number, unit := amount, ""
+	for _, suffix := range []string{"px", "%", "pt"} {
+		if trimmed, ok := strings.CutSuffix(amount, suffix); ok {
+			number, unit = strings.TrimSpace(trimmed), suffix
+			break
+		}
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+		return nil, unrepresentable(value, "expected an amount in pt, px or %%")
+	}
+
+	switch unit {
+	case "%":
+		if parsed < 0 {
+			return nil, unrepresentable(value, "expected a positive percentage")
+		}
+		change := parsed - 100
+		if change == 0 {
+			return nil, nil
+		}
+		return []setting{{key: key, value: formatNumber(change) + "%"}}, nil
+	case "px":
+		return []setting{pixelSetting(key, parsed, "")}, nil
+	}
+	// points, at the 96 dpi most displays are assumed to have
+	return []setting{pixelSetting(key, parsed*96/72, "kitty measures in points, converted to pixels at 96 dpi")}, nil
+}
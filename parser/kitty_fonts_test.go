@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDropRepeatedValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values [][]string
+		want   []string
+	}{
+		{"no repeats", [][]string{{"+zero"}, {"-calt"}}, []string{"+zero", "-calt"}},
+		{"across entries", [][]string{{"-calt"}, {"+ss01"}, {"-calt"}}, []string{"+ss01", "-calt"}},
+		{"within an entry", [][]string{{"-calt", "+zero", "-calt"}}, []string{"+zero", "-calt"}},
+		{"whole entry repeated", [][]string{{"-calt", "-liga"}, {"-calt", "-liga"}}, []string{"-calt", "-liga"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDocument()
+			for _, values := range test.values {
+				config.Entries = append(config.Entries, &Entry{Key: "font-feature", Values: values})
+			}
+			dropRepeatedValues(config, "font-feature")
+			if got := config.GetAll("font-feature"); !slices.Equal(got, test.want) {
+				t.Errorf("font-feature = %q, want %q", got, test.want)
+			}
+			for _, entry := range config.Entries {
+				if len(entry.Values) == 0 {
+					t.Errorf("an empty font-feature entry was kept")
+				}
+			}
+		})
+	}
+}
+
+func TestMergeCodepointMaps(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{"single map", []string{"U+E0A0-U+E0A3=Nerd"}, []string{"U+E0A0-U+E0A3=Nerd"}},
+		{
+			"same family across lines",
+			[]string{"U+E0A0-U+E0A3=Nerd", "U+E0A4=Nerd", "U+F000=Awesome", "U+E0B0=Nerd"},
+			[]string{"U+E0A0-U+E0A4,U+E0B0=Nerd", "U+F000=Awesome"},
+		},
+		{
+			"later family takes shared codepoints",
+			[]string{"U+E000-U+E00F=Nerd", "U+E005=Awesome"},
+			[]string{"U+E000-U+E004,U+E006-U+E00F=Nerd", "U+E005=Awesome"},
+		},
+		{
+			"mapped back to the earlier family",
+			[]string{"U+E000=Nerd", "U+E000-U+E001=Awesome", "U+E000=Nerd"},
+			[]string{"U+E000=Nerd", "U+E001=Awesome"},
+		},
+		{"family fully replaced", []string{"U+E000=Nerd", "U+E000=Awesome"}, []string{"U+E000=Awesome"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDocument()
+			for _, value := range test.values {
+				config.Entries = append(config.Entries, &Entry{Key: "font-codepoint-map", Values: []string{value}})
+			}
+			mergeCodepointMaps(config)
+			if got := config.GetAll("font-codepoint-map"); !slices.Equal(got, test.want) {
+				t.Errorf("font-codepoint-map = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
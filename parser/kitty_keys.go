@@ -0,0 +1,525 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// kitty's default for kitty_mod
+const defaultKittyMod = "ctrl+shift"
+
+// kittyBindings converts kitty map lines into ghostty keybinds
+type kittyBindings struct {
+	// modifiers kitty_mod stands for, already in ghostty form
+	kittyMod []string
+
+	// action_alias and kitten_alias definitions by name
+	actionAliases map[string][]string
+	kittenAliases map[string][]string
+}
+
+// constructor for the kitty binding converter, kitty_mod is resolved once for
+// the whole config like kitty does
+func newKittyBindings(config *Document) *kittyBindings {
+	bindings := &kittyBindings{
+		actionAliases: make(map[string][]string),
+		kittenAliases: make(map[string][]string),
+	}
+
+	kittyMod := defaultKittyMod
+	if value, ok := config.Get("kitty_mod"); ok {
+		kittyMod = value
+	}
+	for _, modifier := range strings.Split(kittyMod, "+") {
+		if normalised, ok := kittyModifiers[strings.ToLower(strings.TrimSpace(modifier))]; ok {
+			bindings.kittyMod = append(bindings.kittyMod, normalised)
+		}
+	}
+
+	// aliases are expanded wherever the map is, later definitions win
+	for _, value := range config.GetAll("action_alias") {
+		if fields := strings.Fields(value); len(fields) > 1 {
+			bindings.actionAliases[fields[0]] = fields[1:]
+		}
+	}
+	for _, value := range config.GetAll("kitten_alias") {
+		if fields := strings.Fields(value); len(fields) > 1 {
+			bindings.kittenAliases[fields[0]] = fields[1:]
+		}
+	}
+	return bindings
+}
+
+// expandAliases replaces a leading action alias with its definition, and the
+// kitten name of a kitten action with its kitten alias. Aliases may refer to
+// other aliases but never to themselves.
+func (b *kittyBindings) expandAliases(action string) string {
+	seen := make(map[string]bool)
+	for {
+		name, args := cutField(action)
+		definition, ok := b.actionAliases[name]
+		if !ok || seen[name] {
+			break
+		}
+		seen[name] = true
+		action = joinFields(strings.Join(definition, " "), args)
+	}
+
+	if name, args := cutField(action); name == "kitten" {
+		kitten, kittenArgs := cutField(args)
+		if definition, ok := b.kittenAliases[kitten]; ok {
+			action = joinFields("kitten "+strings.Join(definition, " "), kittenArgs)
+		}
+	}
+	return action
+}
+
+// cutField splits the first whitespace separated field from the rest of
+// text, the rest keeps its inner whitespace
+func cutField(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if i := strings.IndexAny(text, " \t"); i >= 0 {
+		return text[:i], strings.TrimLeft(text[i:], " \t")
+	}
+	return text, ""
+}
+
+func joinFields(first, rest string) string {
+	if rest == "" {
+		return first
+	}
+	return first + " " + rest
+}
+
+// codex returns the binding transforms, they depend on the config so are
+// built per conversion
+func (b *kittyBindings) codex() map[string]valueTransform {
+	return map[string]valueTransform{
+		"map":                 b.convertMap,
+		"clear_all_shortcuts": clearAllShortcuts,
+	}
+}
+
+// convertMap converts "keys action args" to a ghostty keybind
+func (b *kittyBindings) convertMap(value string) ([]setting, error) {
+	keys, action := cutField(value)
+
+	// options such as --when-focus-on and --mode restrict when the map applies
+	if strings.HasPrefix(keys, "--") {
+		return nil, unrepresentable(value, "ghostty keybinds can't be limited with %s", strings.SplitN(keys, "=", 2)[0])
+	}
+	if action == "" {
+		return nil, unrepresentable(value, "expected keys followed by an action")
+	}
+
+	trigger, err := b.convertTrigger(keys)
+	if err != nil {
+		return nil, err
+	}
+
+	// send_text keeps the whitespace in its text, other actions take fields
+	name, args := cutField(b.expandAliases(action))
+	var translated, approx string
+	if name == "send_text" {
+		translated, approx, err = kittySendText(args)
+	} else {
+		translated, approx, err = translateKittyAction(name, strings.Fields(args))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []setting{{key: "keybind", value: trigger + "=" + translated, approx: approx}}, nil
+}
+
+// clear_all_shortcuts drops every binding defined before it, which is what
+// keybind = clear does in ghostty
+func clearAllShortcuts(value string) ([]setting, error) {
+	clear, ok := parseBool(value)
+	if !ok {
+		return nil, unrepresentable(value, "expected yes or no")
+	}
+	if !clear {
+		return nil, nil
+	}
+	return []setting{{key: "keybind", value: "clear"}}, nil
+}
+
+// convertTrigger converts a kitty key sequence such as kitty_mod+t or
+// ctrl+a>n to ghostty's trigger syntax
+func (b *kittyBindings) convertTrigger(keys string) (string, error) {
+	var steps []string
+	for _, step := range strings.Split(keys, ">") {
+		converted, err := b.convertKeyCombination(step)
+		if err != nil {
+			return "", err
+		}
+		steps = append(steps, converted)
+	}
+	return strings.Join(steps, ">"), nil
+}
+
+func (b *kittyBindings) convertKeyCombination(combination string) (string, error) {
+	parts := strings.Split(combination, "+")
+	// ctrl++ is the plus key
+	if strings.HasSuffix(combination, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	if len(parts) == 0 || parts[len(parts)-1] == "" {
+		return "", unrepresentable(combination, "missing a key")
+	}
+
+	var modifiers []string
+	addModifier := func(modifier string) {
+		for _, existing := range modifiers {
+			if existing == modifier {
+				return
+			}
+		}
+		modifiers = append(modifiers, modifier)
+	}
+
+	for _, part := range parts[:len(parts)-1] {
+		part = strings.ToLower(part)
+		if part == "kitty_mod" {
+			for _, modifier := range b.kittyMod {
+				addModifier(modifier)
+			}
+			continue
+		}
+		modifier, ok := kittyModifiers[part]
+		if !ok {
+			return "", unrepresentable(combination, "ghostty has no %s modifier", part)
+		}
+		addModifier(modifier)
+	}
+
+	key, err := convertKittyKey(parts[len(parts)-1])
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(append(modifiers, key), "+"), nil
+}
+
+// kitty modifier names and symbols in ghostty form, hyper and meta have no
+// ghostty equivalent
+var kittyModifiers = map[string]string{
+	"ctrl":    "ctrl",
+	"control": "ctrl",
+	"⌃":       "ctrl",
+	"shift":   "shift",
+	"⇧":       "shift",
+	"alt":     "alt",
+	"opt":     "alt",
+	"option":  "alt",
+	"⌥":       "alt",
+	"super":   "super",
+	"cmd":     "super",
+	"command": "super",
+	"⌘":       "super",
+}
+
+// kitty key names that are spelled differently in ghostty, names that are
+// the same in both (a, f1, kp_0, page_up) are passed through
+var kittyKeyNames = map[string]string{
+	"return": "enter",
+	"esc":    "escape",
+	"pgup":   "page_up",
+	"pgdn":   "page_down",
+	"grave":  "grave_accent",
+}
+
+// convertKittyKey converts a kitty key name to ghostty's
+func convertKittyKey(key string) (string, error) {
+	lower := strings.ToLower(key)
+	if name, ok := kittyKeyNames[lower]; ok {
+		return name, nil
+	}
+	if name, ok := ghosttyKeyName(lower); ok {
+		return name, nil
+	}
+	return "", unrepresentable(key, "ghostty has no key named %s", key)
+}
+
+// a kitty action and its arguments translated to a ghostty action, approx
+// says how the ghostty action differs when it does
+type kittyAction func(args []string) (action string, approx string, err error)
+
+// translateKittyAction looks the action up in kittyActions
+func translateKittyAction(name string, args []string) (string, string, error) {
+	translate, ok := kittyActions[name]
+	if !ok {
+		return "", "", unrepresentable(name, "ghostty has no equivalent for the kitty %s action", name)
+	}
+	return translate(args)
+}
+
+// same maps an action that behaves the same in ghostty
+func same(action string) kittyAction {
+	return func(args []string) (string, string, error) {
+		return action, "", nil
+	}
+}
+
+// similar maps an action that only comes close in ghostty
+func similar(action, reason string) kittyAction {
+	return func(args []string) (string, string, error) {
+		return action, reason, nil
+	}
+}
+
+// actions that have no ghostty equivalent but are common enough to explain
+func noAction(reason string) kittyAction {
+	return func(args []string) (string, string, error) {
+		return "", "", &unrepresentableError{reason: reason}
+	}
+}
+
+var kittyActions = map[string]kittyAction{
+	// tabs and windows, kitty windows are ghostty splits and kitty OS windows
+	// are ghostty windows
+	"new_tab":                same("new_tab"),
+	"new_tab_with_cwd":       similar("new_tab", "ghostty tabs inherit the working directory when window-inherit-working-directory is on"),
+	"new_os_window":          same("new_window"),
+	"new_os_window_with_cwd": similar("new_window", "ghostty windows inherit the working directory when window-inherit-working-directory is on"),
+	"new_window":             similar("new_split:auto", "kitty windows become ghostty splits"),
+	"new_window_with_cwd":    similar("new_split:auto", "kitty windows become ghostty splits"),
+	"launch":                 kittyLaunch,
+	"close_window":           same("close_surface"),
+	"close_tab":              same("close_tab"),
+	"close_os_window":        same("close_window"),
+	"quit":                   same("quit"),
+	"next_tab":               same("next_tab"),
+	"previous_tab":           same("previous_tab"),
+	"goto_tab":               kittyGotoTab,
+	"move_tab_forward":       same("move_tab:1"),
+	"move_tab_backward":      same("move_tab:-1"),
+	"set_tab_title":          similar("prompt_surface_title", "ghostty titles the focused split rather than the tab"),
+	"next_window":            same("goto_split:next"),
+	"previous_window":        same("goto_split:previous"),
+	"neighboring_window":     kittyNeighboringWindow,
+	"resize_window":          kittyResizeWindow,
+	"reset_window_sizes":     same("equalize_splits"),
+	"toggle_fullscreen":      same("toggle_fullscreen"),
+	"toggle_maximized":       same("toggle_maximize"),
+	"toggle_layout":          kittyToggleLayout,
+	"next_layout":            noAction("ghostty has no window layouts"),
+	"goto_layout":            noAction("ghostty has no window layouts"),
+
+	// no_op hands the key to the program like an unbound key, discard_event
+	// swallows it which is ghostty's ignore
+	"no_op":         same("unbind"),
+	"discard_event": same("ignore"),
+
+	// clipboard
+	"copy_to_clipboard":           same("copy_to_clipboard"),
+	"paste_from_clipboard":        same("paste_from_clipboard"),
+	"paste_from_selection":        same("paste_from_selection"),
+	"copy_or_interrupt":           similar("copy_to_clipboard", "ghostty does not send an interrupt when nothing is selected"),
+	"copy_and_clear_or_interrupt": similar("copy_to_clipboard", "ghostty does not clear the selection or send an interrupt"),
+
+	// scrolling
+	"scroll_line_up":   same("scroll_page_lines:-1"),
+	"scroll_line_down": same("scroll_page_lines:1"),
+	"scroll_page_up":   same("scroll_page_up"),
+	"scroll_page_down": same("scroll_page_down"),
+	"scroll_home":      same("scroll_to_top"),
+	"scroll_end":       same("scroll_to_bottom"),
+	"scroll_to_prompt": kittyScrollToPrompt,
+	"show_scrollback":  similar("write_scrollback_file:open", "ghostty opens the scrollback in the default editor instead of a pager"),
+
+	// fonts and screen
+	"change_font_size": kittyChangeFontSize,
+	"clear_terminal":   kittyClearTerminal,
+
+	// config
+	"edit_config_file":    same("open_config"),
+	"load_config_file":    same("reload_config"),
+	"debug_config":        similar("inspector:toggle", "the ghostty inspector shows the terminal state, not the config"),
+	"kitten":              kittyKitten,
+	"send_key":            kittySendKey,
+	"kitty_shell":         noAction("ghostty has no control shell"),
+	"open_url_with_hints": noAction("ghostty opens links with a modifier click instead of hints"),
+}
+
+func kittyKitten(args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "", "", unrepresentable("", "expected a kitten name")
+	}
+	return "", "", unrepresentable(strings.Join(args, " "), "ghostty can't run the %s kitten", args[0])
+}
+
+// launch opens windows, tabs and OS windows, running a program from a
+// keybind is not something ghostty can do
+func kittyLaunch(args []string) (string, string, error) {
+	action := "new_split:auto"
+	approx := "kitty windows become ghostty splits"
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			return "", "", unrepresentable(strings.Join(args, " "), "ghostty keybinds can't launch a program")
+		}
+
+		option, optionValue, hasValue := strings.Cut(arg, "=")
+		if !hasValue && i+1 < len(args) && !strings.HasPrefix(args[i+1], "--") {
+			i++
+			optionValue = args[i]
+		}
+		switch option {
+		case "--type":
+			switch optionValue {
+			case "window":
+			case "tab":
+				action, approx = "new_tab", ""
+			case "os-window":
+				action, approx = "new_window", ""
+			default:
+				return "", "", unrepresentable(optionValue, "ghostty can't launch a %s", optionValue)
+			}
+		case "--cwd":
+			if optionValue != "current" {
+				return "", "", unrepresentable(optionValue, "ghostty keybinds can't set a working directory")
+			}
+		default:
+			return "", "", unrepresentable(arg, "ghostty has no equivalent for launch %s", option)
+		}
+	}
+	return action, approx, nil
+}
+
+// goto_tab counts from 1, 0 and negative numbers go back through the
+// recently active tabs which ghostty doesn't track
+func kittyGotoTab(args []string) (string, string, error) {
+	if len(args) != 1 {
+		return "", "", unrepresentable(strings.Join(args, " "), "expected a tab number")
+	}
+	tab, err := strconv.Atoi(args[0])
+	if err != nil || tab < 1 {
+		return "", "", unrepresentable(args[0], "ghostty can only go to a tab by position")
+	}
+	return fmt.Sprintf("goto_tab:%d", tab), "", nil
+}
+
+func kittyNeighboringWindow(args []string) (string, string, error) {
+	directions := map[string]string{
+		"left": "left", "right": "right",
+		"up": "up", "top": "up",
+		"down": "down", "bottom": "down",
+	}
+	if len(args) == 1 {
+		if direction, ok := directions[args[0]]; ok {
+			return "goto_split:" + direction, "", nil
+		}
+	}
+	return "", "", unrepresentable(strings.Join(args, " "), "expected left, right, up or down")
+}
+
+// kitty resizes in cells, ghostty in pixels
+const pixelsPerResizeCell = 10
+
+func kittyResizeWindow(args []string) (string, string, error) {
+	directions := map[string]string{
+		"narrower": "left", "wider": "right",
+		"taller": "up", "shorter": "down",
+	}
+	if len(args) == 0 {
+		return "", "", unrepresentable("", "expected a direction")
+	}
+	direction, ok := directions[args[0]]
+	if !ok {
+		return "", "", unrepresentable(args[0], "ghostty can't resize splits that way")
+	}
+	amount := 1
+	if len(args) > 1 {
+		parsed, err := strconv.Atoi(args[1])
+		if err != nil || parsed < 1 {
+			return "", "", unrepresentable(args[1], "expected a number of cells")
+		}
+		amount = parsed
+	}
+	return fmt.Sprintf("resize_split:%s,%d", direction, amount*pixelsPerResizeCell), fmt.Sprintf("ghostty resizes by pixels, using %d per cell", pixelsPerResizeCell), nil
+}
+
+// the stack layout is the closest thing to zooming a ghostty split
+func kittyToggleLayout(args []string) (string, string, error) {
+	if len(args) == 1 && args[0] == "stack" {
+		return "toggle_split_zoom", "ghostty zooms the focused split instead of switching layout", nil
+	}
+	return "", "", unrepresentable(strings.Join(args, " "), "ghostty has no window layouts")
+}
+
+func kittyScrollToPrompt(args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "jump_to_prompt:-1", "", nil
+	}
+	offset, err := strconv.Atoi(args[0])
+	if err != nil {
+		return "", "", unrepresentable(args[0], "expected a number of prompts")
+	}
+	if offset == 0 {
+		return "", "", unrepresentable(args[0], "ghostty can't return to the last visited prompt")
+	}
+	return fmt.Sprintf("jump_to_prompt:%d", offset), "", nil
+}
+
+// change_font_size takes all or current, then +n, -n, *n, /n or an absolute size
+func kittyChangeFontSize(args []string) (string, string, error) {
+	if len(args) != 2 {
+		return "", "", unrepresentable(strings.Join(args, " "), "expected all or current and a size")
+	}
+	approx := ""
+	if args[0] == "all" {
+		approx = "ghostty changes the font size of the focused terminal only"
+	}
+
+	size := args[1]
+	switch {
+	case size == "0":
+		return "reset_font_size", approx, nil
+	case strings.HasPrefix(size, "+"), strings.HasPrefix(size, "-"):
+		delta, err := strconv.ParseFloat(size, 64)
+		if err != nil {
+			return "", "", unrepresentable(size, "expected a font size change")
+		}
+		if delta < 0 {
+			return "decrease_font_size:" + formatNumber(-delta), approx, nil
+		}
+		return "increase_font_size:" + formatNumber(delta), approx, nil
+	case strings.HasPrefix(size, "*"), strings.HasPrefix(size, "/"):
+		return "", "", unrepresentable(size, "ghostty can't scale the font size")
+	}
+
+	absolute, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		return "", "", unrepresentable(size, "expected a font size")
+	}
+	return "set_font_size:" + formatNumber(absolute), approx, nil
+}
+
+// clear_terminal takes what to clear and which windows, ghostty only clears
+// the focused one
+func kittyClearTerminal(args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "", "", unrepresentable("", "expected what to clear")
+	}
+	approx := ""
+	if len(args) > 1 && args[1] != "active" {
+		approx = "ghostty clears the focused terminal only"
+	}
+	switch args[0] {
+	case "reset":
+		return "reset", approx, nil
+	case "clear":
+		return "clear_screen", approx, nil
+	case "scrollback":
+		// ghostty has no action that only clears the scrollback
+		note := "ghostty also clears the screen"
+		if approx != "" {
+			note += "; " + approx
+		}
+		return "clear_screen", note, nil
+	case "scroll":
+		return "clear_screen", "ghostty clears the screen instead of scrolling it into the scrollback", nil
+	}
+	return "", "", unrepresentable(args[0], "ghostty can't clear the terminal that way")
+}
@@ -0,0 +1,134 @@
+package parser
+
+import "testing"
+
+// convert a map line with the bindings of a config made of lines
+func convertKittyMap(t *testing.T, lines map[string][]string, value string) ([]setting, error) {
+	t.Helper()
+	config := NewDocument()
+	for key, values := range lines {
+		for _, v := range values {
+			config.Add(key, v, "kitty.conf", 1)
+		}
+	}
+	return newKittyBindings(config).convertMap(value)
+}
+
+func TestKittyMap(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     map[string][]string
+		value      string
+		want       string
+		wantApprox bool
+	}{
+		{"plain", nil, "ctrl+t new_tab", "ctrl+t=new_tab", false},
+		{"kitty_mod default", nil, "kitty_mod+t new_tab", "ctrl+shift+t=new_tab", false},
+		{"kitty_mod set", map[string][]string{"kitty_mod": {"ctrl+alt"}}, "kitty_mod+t new_tab", "ctrl+alt+t=new_tab", false},
+		{"modifier aliases", nil, "cmd+opt+control+n new_os_window", "super+alt+ctrl+n=new_window", false},
+		{"modifier symbols", nil, "⌘+⇧+t new_tab", "super+shift+t=new_tab", false},
+		{"repeated modifier", nil, "kitty_mod+shift+t new_tab", "ctrl+shift+t=new_tab", false},
+		{"key names", nil, "ctrl+pgup previous_tab", "ctrl+page_up=previous_tab", false},
+		{"plus key", nil, "ctrl++ change_font_size all +1", "ctrl+plus=increase_font_size:1", true},
+		{"sequence", nil, "ctrl+a>n next_tab", "ctrl+a>n=next_tab", false},
+		{"kitty_mod in a sequence", nil, "kitty_mod+a>ctrl+b close_tab", "ctrl+shift+a>ctrl+b=close_tab", false},
+		{"approximated action", nil, "ctrl+enter new_window", "ctrl+enter=new_split:auto", true},
+		{"action args", nil, "ctrl+1 goto_tab 1", "ctrl+1=goto_tab:1", false},
+		{"clear_terminal reset", nil, "ctrl+k clear_terminal reset active", "ctrl+k=reset", false},
+		{"clear_terminal scrollback", nil, "ctrl+k clear_terminal scrollback active", "ctrl+k=clear_screen", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := convertKittyMap(t, test.config, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(settings) != 1 || settings[0].key != "keybind" || settings[0].value != test.want {
+				t.Fatalf("map %s = %v, want keybind = %s", test.value, settings, test.want)
+			}
+			if (settings[0].approx != "") != test.wantApprox {
+				t.Errorf("map %s approximation = %q", test.value, settings[0].approx)
+			}
+		})
+	}
+}
+
+func TestKittyMapUnrepresentable(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+	}{
+		{"no action", "ctrl+t"},
+		{"mode option", "--mode=mw ctrl+t new_tab"},
+		{"focus option", "--when-focus-on=title:vim ctrl+t new_tab"},
+		{"unknown modifier", "hyper+t new_tab"},
+		{"missing key", "ctrl+ new_tab"},
+		{"unknown key", "ctrl+notakey new_tab"},
+		{"unknown action", "ctrl+t not_an_action"},
+		{"action without equivalent", "ctrl+l next_layout"},
+		{"clear_terminal without args", "ctrl+k clear_terminal"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := convertKittyMap(t, nil, test.value)
+			if err == nil {
+				t.Errorf("map %s = %v, want an error", test.value, settings)
+			}
+		})
+	}
+}
+
+func TestKittyUnbindingAndAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		config map[string][]string
+		value  string
+		want   string
+	}{
+		{"no_op", nil, "ctrl+t no_op", "ctrl+t=unbind"},
+		{"discard_event", nil, "ctrl+t discard_event", "ctrl+t=ignore"},
+		{"action alias", map[string][]string{"action_alias": {"tab new_tab"}}, "ctrl+t tab", "ctrl+t=new_tab"},
+		{"alias of an alias", map[string][]string{"action_alias": {"a b", "b new_tab"}}, "ctrl+t a", "ctrl+t=new_tab"},
+		{"later alias wins", map[string][]string{"action_alias": {"tab new_tab", "tab close_tab"}}, "ctrl+t tab", "ctrl+t=close_tab"},
+		{"alias with args", map[string][]string{"action_alias": {"font change_font_size all"}}, "ctrl+0 font 0", "ctrl+0=reset_font_size"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			settings, err := convertKittyMap(t, test.config, test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(settings) != 1 || settings[0].value != test.want {
+				t.Errorf("map %s = %v, want keybind = %s", test.value, settings, test.want)
+			}
+		})
+	}
+
+	// an alias that refers to itself is left as is
+	if _, err := convertKittyMap(t, map[string][]string{"action_alias": {"loop loop"}}, "ctrl+t loop"); err == nil {
+		t.Errorf("a self referencing alias converted")
+	}
+}
+
+func TestClearAllShortcuts(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    []setting
+		wantErr bool
+	}{
+		{"yes", []setting{{key: "keybind", value: "clear"}}, false},
+		{"no", nil, false},
+		{"maybe", nil, true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			settings, err := clearAllShortcuts(test.value)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("clearAllShortcuts(%q) error = %v", test.value, err)
+			}
+			if len(settings) != len(test.want) || len(settings) == 1 && settings[0] != test.want[0] {
+				t.Errorf("clearAllShortcuts(%q) = %v, want %v", test.value, settings, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// kittySendText converts send_text, the modes are a comma separated list of
+// the keyboard modes the text is sent in followed by the text with python
+// style escapes
+func kittySendText(args string) (string, string, error) {
+	modes, text := cutField(args)
+	if modes == "" || text == "" {
+		return "", "", unrepresentable(args, "expected keyboard modes followed by text")
+	}
+
+	approx, err := sendTextModes(modes)
+	if err != nil {
+		return "", "", err
+	}
+
+	payload, err := decodeKittyEscapes(text)
+	if err != nil {
+		return "", "", err
+	}
+	return sendAction(payload), approx, nil
+}
+
+// ghostty sends the text whatever mode the keyboard is in. Text meant for
+// normal mode is close enough, text only for the application cursor or kitty
+// keyboard modes would be sent when the program isn't expecting it.
+func sendTextModes(modes string) (string, error) {
+	normal := false
+	var others []string
+	for _, mode := range strings.Split(modes, ",") {
+		switch mode {
+		case "all":
+			return "", nil
+		case "normal":
+			normal = true
+		case "application", "kitty":
+			others = append(others, mode)
+		default:
+			return "", unrepresentable(modes, "unknown send_text mode %s", mode)
+		}
+	}
+
+	if !normal {
+		return "", unrepresentable(modes, "ghostty can't send text only in %s mode", strings.Join(others, " or "))
+	}
+	if len(others) == 0 {
+		return "ghostty also sends the text in application and kitty keyboard modes", nil
+	}
+	return "", nil
+}
+
+// decodeKittyEscapes decodes the python style escapes kitty accepts in
+// send_text: \n, \t, \xhh, \uhhhh, \Uhhhhhhhh, octal and friends
+func decodeKittyEscapes(text string) (string, error) {
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i == len(text)-1 {
+			builder.WriteByte(text[i])
+			continue
+		}
+
+		i++
+		switch c := text[i]; c {
+		case 'a':
+			builder.WriteByte('\a')
+		case 'b':
+			builder.WriteByte('\b')
+		case 'e':
+			builder.WriteByte(0x1b)
+		case 'f':
+			builder.WriteByte('\f')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 't':
+			builder.WriteByte('\t')
+		case 'v':
+			builder.WriteByte('\v')
+		case '\\', '\'', '"':
+			builder.WriteByte(c)
+		case 'x', 'u', 'U':
+			width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			if i+width >= len(text) {
+				return "", unrepresentable(text, "truncated \\%c escape", c)
+			}
+			code, err := strconv.ParseUint(text[i+1:i+1+width], 16, 32)
+			if err != nil {
+				return "", unrepresentable(text, "invalid \\%c escape", c)
+			}
+			// \xhh is a codepoint like python's, not a raw byte
+			if !utf8.ValidRune(rune(code)) {
+				return "", unrepresentable(text, "invalid codepoint U+%X", code)
+			}
+			builder.WriteRune(rune(code))
+			i += width
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// up to three octal digits
+			end := i + 1
+			for end < len(text) && end < i+3 && text[end] >= '0' && text[end] <= '7' {
+				end++
+			}
+			code, _ := strconv.ParseUint(text[i:end], 8, 16)
+			if code > 0xff {
+				return "", unrepresentable(text, "octal escape out of range")
+			}
+			builder.WriteRune(rune(code))
+			i = end - 1
+		default:
+			// unknown escapes are kept as written, like python does
+			builder.WriteByte('\\')
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String(), nil
+}
+
+// kittySendKey sends the bytes the keys produce. Kitty encodes them for the
+// keyboard mode of the program, ghostty gets the legacy encoding.
+func kittySendKey(args []string) (string, string, error) {
+	if len(args) == 0 {
+		return "", "", unrepresentable("", "expected keys to send")
+	}
+
+	var payload strings.Builder
+	for _, key := range args {
+		encoded, err := legacyKeyBytes(key)
+		if err != nil {
+			return "", "", err
+		}
+		payload.WriteString(encoded)
+	}
+	return sendAction(payload.String()), "ghostty sends the legacy encoding of the keys, programs using the kitty keyboard protocol see different bytes", nil
+}
+
+// the csi final byte or ~ number of the keys that send an escape sequence
+var legacyKeySequences = map[string]string{
+	"up":        "A",
+	"down":      "B",
+	"right":     "C",
+	"left":      "D",
+	"home":      "H",
+	"end":       "F",
+	"insert":    "2~",
+	"delete":    "3~",
+	"page_up":   "5~",
+	"page_down": "6~",
+	"f1":        "P",
+	"f2":        "Q",
+	"f3":        "R",
+	"f4":        "S",
+	"f5":        "15~",
+	"f6":        "17~",
+	"f7":        "18~",
+	"f8":        "19~",
+	"f9":        "20~",
+	"f10":       "21~",
+	"f11":       "23~",
+	"f12":       "24~",
+}
+
+// keys that send a single character
+var legacyKeyCharacters = map[string]string{
+	"enter":     "\r",
+	"tab":       "\t",
+	"escape":    "\x1b",
+	"backspace": "\x7f",
+	"space":     " ",
+}
+
+// legacyKeyBytes encodes a kitty key such as ctrl+c, alt+b or shift+up the
+// way a terminal without keyboard protocol extensions does
+func legacyKeyBytes(combination string) (string, error) {
+	parts := strings.Split(strings.ToLower(combination), "+")
+	if strings.HasSuffix(combination, "++") {
+		parts = append(parts[:len(parts)-2], "+")
+	}
+	var shift, alt, ctrl bool
+	for _, part := range parts[:len(parts)-1] {
+		switch kittyModifiers[part] {
+		case "shift":
+			shift = true
+		case "alt":
+			alt = true
+		case "ctrl":
+			ctrl = true
+		default:
+			return "", unrepresentable(combination, "can't encode the %s modifier", part)
+		}
+	}
+
+	key := parts[len(parts)-1]
+	if name, ok := kittyKeyNames[key]; ok {
+		key = name
+	}
+
+	// cursor, editing and function keys, modifiers go in the second parameter
+	if sequence, ok := legacyKeySequences[key]; ok {
+		modifier := 1
+		if shift {
+			modifier += 1
+		}
+		if alt {
+			modifier += 2
+		}
+		if ctrl {
+			modifier += 4
+		}
+		final := sequence[len(sequence)-1:]
+		number := strings.TrimSuffix(sequence, "~")
+		if modifier == 1 {
+			if len(sequence) == 1 && key[0] == 'f' {
+				// f1 to f4 use ss3
+				return "\x1bO" + sequence, nil
+			}
+			return "\x1b[" + sequence, nil
+		}
+		if final != "~" {
+			number = "1"
+		}
+		return fmt.Sprintf("\x1b[%s;%d%s", number, modifier, final), nil
+	}
+
+	if shift && key == "tab" {
+		return "\x1b[Z", nil
+	}
+
+	text, ok := legacyKeyCharacters[key]
+	if !ok {
+		if utf8.RuneCountInString(key) != 1 {
+			return "", unrepresentable(combination, "can't encode the %s key", key)
+		}
+		text = key
+		if shift {
+			text = strings.ToUpper(text)
+		}
+	}
+
+	if ctrl {
+		if len(text) != 1 || !(text[0] >= '@' && text[0] <= '~' || text[0] == ' ') {
+			return "", unrepresentable(combination, "ctrl+%s has no legacy encoding", key)
+		}
+		text = string(text[0] & 0x1f)
+	}
+	if alt {
+		text = "\x1b" + text
+	}
+	return text, nil
+}
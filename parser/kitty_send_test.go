@@ -0,0 +1,32 @@
+package parser
+
+import "testing"
+
+func TestDecodeKittyEscapes(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`plain`, "plain"},
+		{`\e[A`, "\x1b[A"},
+		{`a\nb\tc`, "a\nb\tc"},
+		{`\x1b`, "\x1b"},
+		{`\xe9`, "é"},
+		{`é`, "é"},
+		{`\U0001F600`, "😀"},
+		{`\033`, "\x1b"},
+		{`\351`, "é"},
+		{`\q`, `\q`},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			got, err := decodeKittyEscapes(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want {
+				t.Errorf("decodeKittyEscapes(%q) = %q, want %q", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package parser
+
+import (
+	"archive/zip"
+	"bytes"
+	"cmp"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// AddThemesDir adds a directory of kitty themes to recognise included
+// themes by
+func (p *KittyParser) AddThemesDir(dir string) {
+	p.themesDirs = append(p.themesDirs, dir)
+}
+
+// kittyCacheDir is where kitty keeps its cache, the themes kitten downloads
+// the theme collection to it
+func kittyCacheDir() string {
+	if dir := os.Getenv("KITTY_CACHE_DIRECTORY"); dir != "" {
+		return dir
+	}
+	if runtime.GOOS == "darwin" {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(homeDir, "Library", "Caches", "kitty")
+		}
+	}
+	if dir := os.Getenv("XDG_CACHE_HOME"); dir != "" {
+		return filepath.Join(dir, "kitty")
+	}
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(homeDir, ".cache", "kitty")
+	}
+	return ""
+}
+
+// recogniseTheme returns the name of the kitty theme the file at path was
+// made from, or "" when it can't be recognised. The name comes from the
+// ## name: header the themes kitten keeps, or from the kitty theme with the
+// same colors.
+func (p *KittyParser) recogniseTheme(path string) string {
+	return cmp.Or(kittyThemeName(path, ""), p.matchKnownTheme(path))
+}
+
+// matchKnownTheme looks for a theme with the colors of the file at path in
+// the user's theme directories and kitty's theme cache
+func (p *KittyParser) matchKnownTheme(path string) string {
+	theme, err := p.Parse(path)
+	if err != nil {
+		return ""
+	}
+	colors := themeColors(theme)
+	if colors == "" {
+		return ""
+	}
+
+	// the themes kitten also lists the user's own themes next to kitty.conf
+	dirs := append([]string{filepath.Join(filepath.Dir(p.configPath), "themes")}, p.themesDirs...)
+	for _, dir := range dirs {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.conf"))
+		for _, file := range files {
+			contents, err := os.ReadFile(file)
+			if err != nil || absolutePath(file) == absolutePath(path) {
+				continue
+			}
+			if name := p.matchTheme(colors, file, contents); name != "" {
+				return name
+			}
+		}
+	}
+
+	archive, err := zip.OpenReader(filepath.Join(kittyCacheDir(), "kitty-themes.zip"))
+	if err != nil {
+		return ""
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if !strings.HasSuffix(file.Name, ".conf") || !strings.Contains(file.Name, "/themes/") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			continue
+		}
+		contents, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			continue
+		}
+		if name := p.matchTheme(colors, file.Name, contents); name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// matchTheme returns the name of the theme in contents when its colors are
+// colors
+func (p *KittyParser) matchTheme(colors, source string, contents []byte) string {
+	theme := NewDocument()
+	if err := p.parseLines(theme, bytes.NewReader(contents), source, nil); err != nil {
+		return ""
+	}
+	if themeColors(theme) != colors {
+		return ""
+	}
+	fallback := strings.TrimSuffix(filepath.Base(source), ".conf")
+	return cmp.Or(themeHeaderName(bytes.NewReader(contents)), fallback)
+}
+
+// themeColors describes the colors a kitty theme converts to, themes with the
+// same description look the same in ghostty
+func themeColors(theme *Document) string {
+	converted := convertKittyTheme(theme, NewReport("kitty"))
+	var lines []string
+	for _, entry := range converted.Entries {
+		if entry.Disabled {
+			continue
+		}
+		for _, value := range entry.Values {
+			lines = append(lines, entry.Key+" = "+value)
+		}
+	}
+	slices.Sort(lines)
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeThemeCache writes a kitty-themes.zip like the themes kitten downloads
+func writeThemeCache(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, "kitty-themes.zip"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	archive := zip.NewWriter(file)
+	for name, contents := range files {
+		writer, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(contents)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRecogniseTheme(t *testing.T) {
+	const colors = "background #101010\nforeground #eeeeee\n"
+	tests := []struct {
+		name      string
+		files     map[string]string
+		themesDir map[string]string
+		cache     map[string]string
+		want      string
+	}{
+		{
+			name:  "header",
+			files: map[string]string{"current-theme.conf": "## name: Tokyo Night\n" + colors},
+			want:  "Tokyo Night",
+		},
+		{
+			name: "themes next to the config",
+			files: map[string]string{
+				"current-theme.conf": colors,
+				"themes/Mine.conf":   colors,
+			},
+			want: "Mine",
+		},
+		{
+			name:      "added themes dir",
+			files:     map[string]string{"current-theme.conf": colors},
+			themesDir: map[string]string{"Other.conf": "background #000000\n", "Added.conf": colors},
+			want:      "Added",
+		},
+		{
+			name:  "kitty's theme cache",
+			files: map[string]string{"current-theme.conf": colors},
+			cache: map[string]string{
+				"kitty-themes-master/README.md":          colors,
+				"kitty-themes-master/themes/Cached.conf": "## name: Cached Theme\n" + colors,
+			},
+			want: "Cached Theme",
+		},
+		{
+			name:  "colors in a different order",
+			files: map[string]string{"current-theme.conf": "foreground #eeeeee\nbackground #101010\n", "themes/Mine.conf": colors},
+			want:  "Mine",
+		},
+		{
+			name:  "different colors",
+			files: map[string]string{"current-theme.conf": colors, "themes/Mine.conf": "background #101010\n"},
+			want:  "",
+		},
+		{
+			name:  "no colors",
+			files: map[string]string{"current-theme.conf": "font_size 12\n", "themes/Mine.conf": "font_size 12\n"},
+			want:  "",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("KITTY_CACHE_DIRECTORY", writeThemeCache(t, test.cache))
+			test.files["kitty.conf"] = "include current-theme.conf\n"
+			dir := writeFiles(t, test.files)
+
+			kittyParser := NewKittyParser(filepath.Join(dir, "kitty.conf"))
+			if test.themesDir != nil {
+				kittyParser.AddThemesDir(writeFiles(t, test.themesDir))
+			}
+			if got := kittyParser.recogniseTheme(filepath.Join(dir, "current-theme.conf")); got != test.want {
+				t.Errorf("recogniseTheme() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestKittyThemeInclude(t *testing.T) {
+	tests := []struct {
+		name       string
+		theme      string
+		want       string
+		background string
+	}{
+		{"shipped", "## name: Shipped Dark\nbackground #101010\n", "Shipped Dark", ""},
+		{"similar name", "## name: shipped_dark\nbackground #101010\n", "Shipped Dark", ""},
+		{"not shipped", "## name: Homemade\nbackground #101010\n", "", "#101010"},
+		{"not recognised", "background #101010\n", "", "#101010"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useGhosttyThemes(t, "Shipped Dark")
+			t.Setenv("KITTY_CACHE_DIRECTORY", t.TempDir())
+			converted, _ := convertKittyFiles(t, map[string]string{
+				"kitty.conf":         "include current-theme.conf\nfont_size 12\n",
+				"current-theme.conf": test.theme,
+			})
+
+			if got, _ := converted.Get("theme"); got != test.want {
+				t.Errorf("theme = %q, want %q", got, test.want)
+			}
+			if got, _ := converted.Get("background"); got != test.background {
+				t.Errorf("background = %q, want %q", got, test.background)
+			}
+		})
+	}
+}
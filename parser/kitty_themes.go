@@ -0,0 +1,162 @@
+package parser
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"ghostty-ghost/ghostty"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// a theme kitty switches to with the OS appearance
+type kittyAutoTheme struct {
+	appearance string
+	path       string
+}
+
+// the auto theme files kitty looks for next to kitty.conf, by appearance
+var kittyAutoThemeFiles = []struct{ appearance, file string }{
+	{"light", "light-theme.auto.conf"},
+	{"dark", "dark-theme.auto.conf"},
+	{"no-preference", "no-preference-theme.auto.conf"},
+}
+
+// findAutoThemes returns the auto theme files next to the config
+func (p *KittyParser) findAutoThemes() []kittyAutoTheme {
+	var themes []kittyAutoTheme
+	for _, autoTheme := range kittyAutoThemeFiles {
+		path := filepath.Join(filepath.Dir(p.configPath), autoTheme.file)
+		if _, err := os.Stat(path); err == nil {
+			themes = append(themes, kittyAutoTheme{autoTheme.appearance, path})
+		}
+	}
+	return themes
+}
+
+func hasAutoTheme(autoThemes []kittyAutoTheme, appearance string) bool {
+	return slices.ContainsFunc(autoThemes, func(autoTheme kittyAutoTheme) bool {
+		return autoTheme.appearance == appearance
+	})
+}
+
+// autoThemesCoverAll is true when every appearance has an auto theme, so
+// the colors in the config never make a theme of their own
+func autoThemesCoverAll(autoThemes []kittyAutoTheme) bool {
+	return hasAutoTheme(autoThemes, "no-preference") ||
+		hasAutoTheme(autoThemes, "light") && hasAutoTheme(autoThemes, "dark")
+}
+
+// convertAutoThemes converts the auto themes into ghostty themes and returns
+// the value of the theme setting that picks between them. Appearances without
+// an auto theme use baseName, or baseColors, the colors from the config.
+func (p *KittyParser) convertAutoThemes(autoThemes []kittyAutoTheme, baseName string, baseColors *Document) string {
+	// ghostty only knows light and dark
+	noPreferenceUnused := hasAutoTheme(autoThemes, "light") && hasAutoTheme(autoThemes, "dark")
+
+	names := make(map[string]string)
+	for _, autoTheme := range autoThemes {
+		themeFile, err := p.Parse(autoTheme.path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Could not read %s: %v", autoTheme.path, err)))
+			continue
+		}
+		if autoTheme.appearance == "no-preference" && noPreferenceUnused {
+			for _, entry := range themeFile.Entries {
+				p.report.add(entry, StatusUnsupported, nil, "ghostty has no theme for systems without a light or dark preference")
+			}
+			continue
+		}
+
+		if name, ok := ghostty.FindTheme(p.recogniseTheme(autoTheme.path)); ok {
+			for _, entry := range themeFile.Entries {
+				p.report.add(entry, StatusTransformed, []string{"theme"}, "recognised as the ghostty "+name+" theme")
+			}
+			names[autoTheme.appearance] = name
+			continue
+		}
+
+		// kitty applies the auto theme over the colors in the config, so
+		// the config colors it doesn't set are part of the theme
+		name := kittyThemeName(autoTheme.path, "kitty-"+autoTheme.appearance)
+		p.themes = append(p.themes, Theme{name, layerColors(baseColors, convertKittyTheme(themeFile, p.report))})
+		names[autoTheme.appearance] = name
+	}
+
+	if baseName == "" && baseColors.Len() > 0 && !autoThemesCoverAll(autoThemes) {
+		baseName = "kitty"
+		p.themes = append(p.themes, Theme{baseName, baseColors})
+	}
+
+	light := cmp.Or(names["light"], names["no-preference"], baseName)
+	dark := cmp.Or(names["dark"], names["no-preference"], baseName)
+	// with a single theme it is used for both appearances
+	light, dark = cmp.Or(light, dark), cmp.Or(dark, light)
+	if light == dark {
+		return light
+	}
+	return "light:" + light + ",dark:" + dark
+}
+
+// layerColors returns the colors of theme on top of the base colors it
+// doesn't set, palette entries are compared by index
+func layerColors(base, theme *Document) *Document {
+	keys := make(map[string]bool)
+	for _, entry := range theme.Lookup("palette") {
+		for _, value := range entry.Values {
+			index, _, _ := strings.Cut(value, "=")
+			keys["palette "+index] = true
+		}
+	}
+	for _, entry := range theme.Entries {
+		if !entry.Disabled && entry.Key != "palette" {
+			keys[entry.Key] = true
+		}
+	}
+
+	layered := NewDocument()
+	for _, entry := range base.Entries {
+		if entry.Disabled || entry.Key == "" || keys[entry.Key] {
+			continue
+		}
+		for _, value := range entry.Values {
+			index, _, _ := strings.Cut(value, "=")
+			if entry.Key == "palette" && keys["palette "+index] {
+				continue
+			}
+			layered.Add(entry.Key, value, entry.Source, entry.Line)
+		}
+	}
+	layered.Append(theme.Entries...)
+	return layered
+}
+
+// kittyThemeName reads the name from the ## name: header the themes kitten
+// writes, falling back to fallback for other files
+func kittyThemeName(path, fallback string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return fallback
+	}
+	defer file.Close()
+	return cmp.Or(themeHeaderName(file), fallback)
+}
+
+// themeHeaderName returns the name in a ## name: header, "" without a usable one
+func themeHeaderName(reader io.Reader) string {
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "## name:"); ok {
+			// the name becomes a file name, names that aren't one are ignored
+			name = strings.TrimSpace(strings.ReplaceAll(name, string(filepath.Separator), "-"))
+			if !validThemeName(name) {
+				return ""
+			}
+			return name
+		}
+	}
+	return ""
+}
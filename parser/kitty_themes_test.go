@@ -0,0 +1,188 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// useGhosttyThemes makes names the only themes ghostty ships during the test
+func useGhosttyThemes(t *testing.T, names ...string) {
+	t.Helper()
+	resources := t.TempDir()
+	if err := os.Mkdir(filepath.Join(resources, "themes"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(resources, "themes", name), []byte("background = #000000\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("GHOSTTY_RESOURCES_DIR", resources)
+	t.Setenv("XDG_DATA_DIRS", t.TempDir())
+}
+
+// convertKittyFiles converts the kitty.conf in files, returning the config
+// and the themes the conversion made
+func convertKittyFiles(t *testing.T, files map[string]string) (*Document, map[string]*Document) {
+	t.Helper()
+	dir := writeFiles(t, files)
+	configPath := filepath.Join(dir, "kitty.conf")
+	kittyParser := NewKittyParser(configPath)
+	config, err := kittyParser.Parse(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := kittyParser.ConvertToGhostty(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	themes := make(map[string]*Document)
+	for _, theme := range kittyParser.Themes() {
+		themes[theme.Name] = theme.Config
+	}
+	return converted, themes
+}
+
+// the enabled key = value lines of a document
+func documentLines(config *Document) []string {
+	var lines []string
+	for _, entry := range config.Entries {
+		if entry.Disabled || entry.Key == "" {
+			continue
+		}
+		for _, value := range entry.Values {
+			lines = append(lines, entry.Key+" = "+value)
+		}
+	}
+	return lines
+}
+
+func TestLayerColors(t *testing.T) {
+	tests := []struct {
+		name        string
+		base, theme []string
+		want        []string
+	}{
+		{"no base", nil, []string{"background = #111111"}, []string{"background = #111111"}},
+		{"theme wins", []string{"background = #000000"}, []string{"background = #111111"}, []string{"background = #111111"}},
+		{
+			"base fills in",
+			[]string{"cursor-color = #ff0000", "background = #000000"},
+			[]string{"background = #111111"},
+			[]string{"cursor-color = #ff0000", "background = #111111"},
+		},
+		{
+			"palette by index",
+			[]string{"palette = 1=#010101", "palette = 2=#020202"},
+			[]string{"palette = 2=#222222"},
+			[]string{"palette = 1=#010101", "palette = 2=#222222"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			base, theme := NewDocument(), NewDocument()
+			for _, line := range test.base {
+				base.Append(ParseGhosttyConfig("base", line).Entries...)
+			}
+			for _, line := range test.theme {
+				theme.Append(ParseGhosttyConfig("theme", line).Entries...)
+			}
+			if got := documentLines(layerColors(base, theme)); !slices.Equal(got, test.want) {
+				t.Errorf("layerColors() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestConvertAutoThemes(t *testing.T) {
+	const (
+		config = "font_size 12\nbackground #000000\ncursor #ff0000\n"
+		light  = "## name: My Light\nbackground #ffffff\n"
+		dark   = "## name: My Dark\nbackground #101010\n"
+	)
+	tests := []struct {
+		name   string
+		files  map[string]string
+		theme  string
+		themes map[string][]string
+	}{
+		{
+			"light only",
+			map[string]string{"kitty.conf": config, "light-theme.auto.conf": light},
+			"light:My Light,dark:kitty",
+			map[string][]string{
+				"My Light": {"cursor-color = #ff0000", "background = #ffffff"},
+				"kitty":    {"background = #000000", "cursor-color = #ff0000"},
+			},
+		},
+		{
+			"light and dark",
+			map[string]string{"kitty.conf": config, "light-theme.auto.conf": light, "dark-theme.auto.conf": dark},
+			"light:My Light,dark:My Dark",
+			map[string][]string{
+				"My Light": {"cursor-color = #ff0000", "background = #ffffff"},
+				"My Dark":  {"cursor-color = #ff0000", "background = #101010"},
+			},
+		},
+		{
+			"no preference for both",
+			map[string]string{"kitty.conf": config, "no-preference-theme.auto.conf": dark},
+			"My Dark",
+			map[string][]string{"My Dark": {"cursor-color = #ff0000", "background = #101010"}},
+		},
+		{
+			"no preference unused",
+			map[string]string{
+				"kitty.conf":                    config,
+				"light-theme.auto.conf":         light,
+				"dark-theme.auto.conf":          dark,
+				"no-preference-theme.auto.conf": "background #333333\n",
+			},
+			"light:My Light,dark:My Dark",
+			map[string][]string{
+				"My Light": {"cursor-color = #ff0000", "background = #ffffff"},
+				"My Dark":  {"cursor-color = #ff0000", "background = #101010"},
+			},
+		},
+		{
+			"theme without a name",
+			map[string]string{"kitty.conf": "font_size 12\n", "dark-theme.auto.conf": "background #101010\n"},
+			"kitty-dark",
+			map[string][]string{"kitty-dark": {"background = #101010"}},
+		},
+		{
+			"theme ghostty ships",
+			map[string]string{"kitty.conf": config, "light-theme.auto.conf": light, "dark-theme.auto.conf": "## name: Shipped Dark\nbackground #101010\n"},
+			"light:My Light,dark:Shipped Dark",
+			map[string][]string{"My Light": {"cursor-color = #ff0000", "background = #ffffff"}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useGhosttyThemes(t, "Shipped Dark")
+			converted, themes := convertKittyFiles(t, test.files)
+
+			if got, _ := converted.Get("theme"); got != test.theme {
+				t.Errorf("theme = %q, want %q", got, test.theme)
+			}
+			if _, ok := converted.Get("background"); ok {
+				t.Errorf("the config kept its background, it belongs to the themes")
+			}
+			if len(themes) != len(test.themes) {
+				t.Errorf("got %d themes, want %d", len(themes), len(test.themes))
+			}
+			for name, want := range test.themes {
+				theme, ok := themes[name]
+				if !ok {
+					t.Errorf("no %s theme", name)
+					continue
+				}
+				if got := documentLines(theme); !slices.Equal(got, want) {
+					t.Errorf("theme %s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
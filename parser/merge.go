@@ -0,0 +1,135 @@
+package parser
+
+import (
+	"fmt"
+	"ghostty-ghost/ghostty"
+	"os"
+	"slices"
+	"strings"
+)
+
+// markers around the part of a ghostty config that merge mode owns, anything
+// outside them is never touched
+const (
+	managedBlockStart = "# >>> ghostty-ghost >>>"
+	managedBlockEnd   = "# <<< ghostty-ghost <<<"
+)
+
+// managedBlock is the byte offsets and lines of the managed block in a config
+type managedBlock struct {
+	start, end         int
+	startLine, endLine int
+}
+
+// findManagedBlock locates the managed block in contents, found is false when
+// there isn't one yet
+func findManagedBlock(contents string) (block managedBlock, found bool, err error) {
+	offset := 0
+	startLine := 0
+	for i, line := range strings.SplitAfter(contents, "\n") {
+		marker := strings.TrimRight(line, "\r\n")
+		switch marker {
+		case managedBlockStart:
+			if found || startLine != 0 {
+				return block, false, fmt.Errorf("line %d: more than one %q marker", i+1, managedBlockStart)
+			}
+			block.start = offset
+			startLine = i + 1
+		case managedBlockEnd:
+			if startLine == 0 {
+				return block, false, fmt.Errorf("line %d: %q without a %q marker before it", i+1, managedBlockEnd, managedBlockStart)
+			}
+			if found {
+				return block, false, fmt.Errorf("line %d: more than one %q marker", i+1, managedBlockEnd)
+			}
+			block.end = offset + len(line)
+			block.startLine, block.endLine = startLine, i+1
+			found = true
+		}
+		offset += len(line)
+	}
+
+	if startLine != 0 && !found {
+		return block, false, fmt.Errorf("line %d: %q has no %q marker after it", startLine, managedBlockStart, managedBlockEnd)
+	}
+	return block, found, nil
+}
+
+// MergeGhosttyConfig puts the converted config into the managed block of an
+// existing ghostty config. The block is replaced when there is one and
+// appended otherwise, the rest of existing is kept byte for byte.
+func MergeGhosttyConfig(source, existing string, config *Document) (string, error) {
+	block, found, err := findManagedBlock(existing)
+	if err != nil {
+		return "", fmt.Errorf("failed to find the managed block in %s: %w", source, err)
+	}
+
+	// match the line endings of the existing file
+	newline := "\n"
+	if strings.Contains(existing, "\r\n") {
+		newline = "\r\n"
+	}
+	rendered := managedBlockStart + "\n" + RenderGhostty(config) + managedBlockEnd + "\n"
+	rendered = strings.ReplaceAll(rendered, "\n", newline)
+
+	warnOverlappingKeys(source, existing, block, found, config)
+
+	if found {
+		return existing[:block.start] + rendered + existing[block.end:], nil
+	}
+
+	if existing == "" {
+		return rendered, nil
+	}
+	if !strings.HasSuffix(existing, "\n") {
+		existing += newline
+	}
+	return existing + newline + rendered, nil
+}
+
+// warn about converted keys the user also sets outside the managed block,
+// settings later in the file win so the result may not be what they expect
+func warnOverlappingKeys(source, existing string, block managedBlock, found bool, config *Document) {
+	converted := make(map[string]bool)
+	clearsKeybinds := false
+	for _, entry := range config.Entries {
+		if entry.Key == "" || entry.Disabled {
+			continue
+		}
+		if !ghostty.IsRepeatable(entry.Key) {
+			converted[entry.Key] = true
+		}
+		if entry.Key == "keybind" && slices.Contains(entry.Values, "clear") {
+			clearsKeybinds = true
+		}
+	}
+
+	for _, entry := range ParseGhosttyConfig(source, existing).Entries {
+		if found && entry.Line >= block.startLine && entry.Line <= block.endLine {
+			continue
+		}
+		if converted[entry.Key] {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("%s is also set outside the managed block at %s:%d", entry.Key, source, entry.Line)))
+		}
+		// keybind = clear drops every keybind before it, the block is
+		// appended when there isn't one yet
+		if clearsKeybinds && entry.Key == "keybind" && (!found || entry.Line < block.startLine) {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("keybind = clear in the managed block drops the keybind at %s:%d", source, entry.Line)))
+		}
+	}
+}
+
+// write the converted config into the managed block of the ghostty config at
+// filepath, keeping a backup of the original
+func WriteMergedGhosttyConfig(filepath string, config *Document) error {
+	existing, err := os.ReadFile(filepath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", filepath, err)
+	}
+
+	merged, err := MergeGhosttyConfig(filepath, string(existing), config)
+	if err != nil {
+		return err
+	}
+	return writeWithBackup(filepath, merged)
+}
@@ -0,0 +1,68 @@
+package parser
+
+import "testing"
+
+func TestMergeGhosttyConfig(t *testing.T) {
+	config := NewDocument()
+	config.Add("font-size", "12", "", 0)
+	config.Add("theme", "Dracula", "", 0)
+	block := managedBlockStart + "\nfont-size = 12\ntheme = Dracula\n" + managedBlockEnd + "\n"
+
+	tests := []struct {
+		name     string
+		existing string
+		want     string
+	}{
+		{"new file", "", block},
+		{"no block", "window-decoration = false\n", "window-decoration = false\n\n" + block},
+		{"no trailing newline", "window-decoration = false", "window-decoration = false\n\n" + block},
+		{
+			"replaces the block",
+			"a = 1\n" + managedBlockStart + "\nfont-size = 10\n" + managedBlockEnd + "\nb = 2\n",
+			"a = 1\n" + block + "b = 2\n",
+		},
+		{
+			"keeps crlf",
+			"a = 1\r\n",
+			"a = 1\r\n\r\n" + managedBlockStart + "\r\nfont-size = 12\r\ntheme = Dracula\r\n" + managedBlockEnd + "\r\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, err := MergeGhosttyConfig("config", test.existing, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if merged != test.want {
+				t.Errorf("MergeGhosttyConfig() =\n%q\nwant\n%q", merged, test.want)
+			}
+
+			// merging again changes nothing
+			again, err := MergeGhosttyConfig("config", merged, config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if again != merged {
+				t.Errorf("merging twice =\n%q\nwant\n%q", again, merged)
+			}
+		})
+	}
+}
+
+func TestFindManagedBlockErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+	}{
+		{"start only", managedBlockStart + "\n"},
+		{"end only", managedBlockEnd + "\n"},
+		{"two blocks", managedBlockStart + "\n" + managedBlockEnd + "\n" + managedBlockStart + "\n" + managedBlockEnd + "\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, _, err := findManagedBlock(test.contents); err == nil {
+				t.Errorf("findManagedBlock(%q) succeeded, want an error", test.contents)
+			}
+		})
+	}
+}
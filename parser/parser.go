@@ -3,10 +3,13 @@ package parser
 import (
 	"bufio"
 	"fmt"
+	"ghostty-ghost/ghostty"
+	"io"
 	"os"
-	"path"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -22,82 +25,25 @@ func colorWarning(message string) string {
 }
 
 type ConfigParser interface {
-	Parse(filepath string) (map[string]string, error)
-	Write(filepath string, config map[string]string) error
-	ConvertToGhostty(config map[string]string) (map[string]string, error)
+	Parse(filepath string) (*Document, error)
+	Write(filepath string, config *Document) error
+	ConvertToGhostty(config *Document) (*Document, error)
+	Report() *Report
+	Themes() []Theme
 }
 
 type KittyParser struct {
 	configPath string
-}
-type AlacrittyParser struct {
-	isParsingTheme    bool
-	recursionDepth    int
-	maxRecursionDepth int
-	configPath        string
-}
+	report     *Report
+	themes     []Theme
 
-// sort keys alphabetically
-func sortKeysAlphabetically(config map[string]string) []string {
-	keys := make([]string, 0, len(config))
-	for key := range config {
-		keys = append(keys, key)
-	}
-	sort.Strings(keys)
-	return keys
+	// kitty themes to recognise included themes by, besides kitty's own
+	themesDirs []string
 }
 
 // kittywriter
-func (p *KittyParser) Write(filepath string, config map[string]string) error {
-	// Create directory if it doesn't exist
-	dir := path.Dir(filepath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	// before writing the file backeup the old one if there is one
-	if _, err := os.Stat(filepath); err == nil {
-		backupPath := filepath + ".bak"
-		// Remove existing backup if it exists
-		if _, err := os.Stat(backupPath); err == nil {
-			if err := os.Remove(backupPath); err != nil {
-				return fmt.Errorf("failed to remove existing backup: %w", err)
-			}
-		}
-
-		// Create new backup
-		if err := os.Rename(filepath, backupPath); err != nil {
-			return fmt.Errorf("failed to create backup: %w", err)
-		}
-	}
-
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	// sort the keys alphabetically
-	keys := sortKeysAlphabetically(config)
-
-	// write to the file
-	writer := bufio.NewWriter(file)
-	for _, key := range keys {
-		// and = is already added to pallete in the func ConvertToGhostty
-		if strings.Contains(key, "palette") {
-			_, err := writer.WriteString(fmt.Sprintf("%s%s\n", key, config[key]))
-			if err != nil {
-				return err
-			}
-		} else {
-			// if the key  contains palette dont put a space after =
-			_, err := writer.WriteString(fmt.Sprintf("%s = %s\n", key, config[key]))
-			if err != nil {
-				return err
-			}
-		}
-	}
-	return writer.Flush()
+func (p *KittyParser) Write(filepath string, config *Document) error {
+	return writeGhosttyConfig(filepath, config)
 }
 
 // method to get the appriopriate parser
@@ -114,39 +60,55 @@ func GetParser(terminalType string, configPath string) (ConfigParser, error) {
 	}
 }
 
+// Report describes what happened to every setting in the last conversion
+func (p *KittyParser) Report() *Report {
+	return p.report
+}
+
+// Themes are the theme files the last conversion refers to
+func (p *KittyParser) Themes() []Theme {
+	return p.themes
+}
+
 // a constructer for kittyParser
 func NewKittyParser(configPath string) *KittyParser {
 	return &KittyParser{configPath: configPath}
 }
 
-// constructor for alacrittyParser
-func NewAlacrittyParser(configPath string) *AlacrittyParser {
-	return &AlacrittyParser{
-		configPath:        configPath,
-		maxRecursionDepth: 2,
-		recursionDepth:    0,
-		isParsingTheme:    false,
-	}
-}
-
-// Parse the config file
-func (p *KittyParser) Parse(filepath string) (map[string]string, error) {
-	config := make(map[string]string)
+// Parse the config file and everything it includes
+func (p *KittyParser) Parse(filepath string) (*Document, error) {
+	config := NewDocument()
 	file, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open config file: %w", err)
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
+	if err := p.parseLines(config, file, filepath, []string{absolutePath(filepath)}); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// parse config lines from reader into config. Includes are expanded in place
+// so later settings override earlier ones, stack holds the files currently
+// being read to detect include cycles.
+func (p *KittyParser) parseLines(config *Document, reader io.Reader, source string, stack []string) error {
+	scanner := bufio.NewScanner(reader)
 	lineNum := 0
+	var comments []string
 
 	for scanner.Scan() {
 		lineNum++
 		line := strings.TrimSpace(scanner.Text())
 
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+		// Skip empty lines and keep comments for the next entry
+		if line == "" {
+			comments = nil
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			comments = append(comments, strings.TrimSpace(strings.TrimPrefix(line, "#")))
 			continue
 		}
 
@@ -166,541 +128,471 @@ func (p *KittyParser) Parse(filepath string) (map[string]string, error) {
 		}
 
 		if key == "" {
-			return nil, fmt.Errorf("empty key found at line %d", lineNum)
+			return fmt.Errorf("empty key found at line %d", lineNum)
 		}
 
-		config[key] = value
+		entry := config.Add(key, value, source, lineNum)
+		entry.Comments = comments
+		comments = nil
+
+		// the include directive stays in the document, followed by the
+		// settings it pulled in
+		switch key {
+		case "include", "globinclude", "envinclude", "geninclude":
+			p.include(config, key, value, source, stack)
+		}
 	}
 
 	// Check for scanner errors
 	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error reading config: %w", err)
+		return fmt.Errorf("error reading config: %w", err)
 	}
 
-	return config, nil
+	return nil
 }
 
-func (p *KittyParser) ConvertToGhostty(kittyConfig map[string]string) (map[string]string, error) {
-	ghosttyConfig := make(map[string]string)
-	var unmappedKeys []string
-
-	for kittyKey, value := range kittyConfig {
+// expand an include directive the way kitty does
+func (p *KittyParser) include(config *Document, directive, value, source string, stack []string) {
+	switch directive {
+	case "include":
+		p.includeFile(config, p.resolveIncludePath(value, source), stack)
 
-		if ghosttyKey, exists := kittyToGhosttyCodex[kittyKey]; exists {
-
-			ghosttyConfig[ghosttyKey] = value
-		} else {
-			// handle unmapped keys
-			unmappedKeys = append(unmappedKeys, kittyKey)
+	case "globinclude":
+		matches, err := filepath.Glob(p.resolveIncludePath(value, source))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Invalid globinclude pattern %q: %v", value, err)))
+			return
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			p.includeFile(config, match, stack)
 		}
-	}
 
-	// handle theme conversion from kitty to ghostty
-	for key, value := range kittyConfig {
-		if key == "include" && strings.Contains(value, "themes/") {
-			// split the path to get the theme name
-			parts := strings.Split(value, "/")
-			if len(parts) > 0 {
-				// get the last part and remove the extension
-				themeName := parts[len(parts)-1]
-				themeName = strings.TrimSuffix(themeName, ".conf")
-				ghosttyConfig["theme"] = themeName
+	case "envinclude":
+		// the value of every matching environment variable is config text
+		var names []string
+		for _, variable := range os.Environ() {
+			name, _, _ := strings.Cut(variable, "=")
+			if matched, err := filepath.Match(value, name); err == nil && matched {
+				names = append(names, name)
 			}
-		} else if strings.Contains(value, "current-theme.conf") {
-			// parse the theme file
-			themeConfig := p.parseKittyThemeFile(value)
-
-			// add all the values to the ghostty config
-			for key, value := range themeConfig {
-				ghosttyConfig[key] = value
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			envSource := "env:" + name
+			if slices.Contains(stack, envSource) {
+				continue
+			}
+			err := p.parseLines(config, strings.NewReader(os.Getenv(name)), envSource, append(stack, envSource))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Failed to read %s: %v", envSource, err)))
 			}
 		}
-	}
 
-	// add unmapped keys to the ghostty config but comment them out
-	// add a cmment saying that they are unmapped settings
-	ghosttyConfig["# Unmapped settings"] = ""
-	for _, key := range unmappedKeys {
-		ghosttyConfig["# "+key] = kittyConfig[key]
+	case "geninclude":
+		// running programs from a config conversion is not something we do
+		fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Skipping geninclude %s, generated config is not supported", value)))
 	}
-
-	return ghosttyConfig, nil
 }
 
-// helper function to parse kitty theme file
-func (p *KittyParser) parseKittyThemeFile(themeFile string) map[string]string {
-	configDir := filepath.Dir(p.configPath)
-	fullThemePath := filepath.Join(configDir, themeFile)
-
-	themeConfig := make(map[string]string)
+// read an included file into config
+func (p *KittyParser) includeFile(config *Document, includePath string, stack []string) {
+	if slices.Contains(stack, absolutePath(includePath)) {
+		fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Include cycle detected, skipping %s", includePath)))
+		return
+	}
 
-	// get the contents of the theme file
-	file, err := os.Open(fullThemePath)
+	file, err := os.Open(includePath)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error opening theme file: %v\n", err)
-		fmt.Print("Theme file not found\n")
-		return nil
+		fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Could not include %s: %v", includePath, err)))
+		return
 	}
 	defer file.Close()
 
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		parts := strings.SplitN(line, " ", 2)
-		if len(parts) != 2 {
-			continue
-		}
-		key := strings.TrimSpace(parts[0])
-		value := strings.TrimSpace(parts[1])
-
-		if key == "" {
-			fmt.Print("Empty key found in theme file, ignored\n")
-			continue
-		}
-
-		themeConfig[key] = value
-	}
-
-	// check for scanner errors
-	if err := scanner.Err(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading theme file: %v\n", err)
-		return nil
+	if err := p.parseLines(config, file, includePath, append(stack, absolutePath(includePath))); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", colorWarning(fmt.Sprintf("Failed to read %s: %v", includePath, err)))
 	}
-
-	return ConvertKittyThemeToGhostty(themeConfig)
-
 }
 
-// handle the kitty theme conversion
-func ConvertKittyThemeToGhostty(themeFile map[string]string) map[string]string {
-
-	ghosttyThemeConfig := make(map[string]string)
-	var unmappedKeys []string
-
-	for kittyKey, value := range themeFile {
-		if ghosttyKey, exists := kittyToGhosttyThemeCodex[kittyKey]; exists {
-			// format the ghostty key correctly eg palette = 26=#005fd7
-			if strings.Contains(ghosttyKey, " = ") {
-				parts := strings.SplitN(ghosttyKey, " = ", 2)
-				baseKey := parts[0]
-				modifier := parts[1]
-
-				ghosttyThemeConfig[baseKey+" = "+modifier] = value
-			} else {
-				ghosttyThemeConfig[ghosttyKey] = value
-			}
-		} else {
-			// hanndle unmapped keys
-			unmappedKeys = append(unmappedKeys, kittyKey)
+// expand ~ and environment variables, relative paths are resolved against the
+// directory of the including file (the config dir for envinclude)
+func (p *KittyParser) resolveIncludePath(value, source string) string {
+	includePath := os.ExpandEnv(value)
+	if includePath == "~" || strings.HasPrefix(includePath, "~/") {
+		if homeDir, err := os.UserHomeDir(); err == nil {
+			includePath = filepath.Join(homeDir, includePath[1:])
 		}
 	}
-
-	// add unmapped keys to the ghostty theme config but comment them out
-	ghosttyThemeConfig["# Unmapped settings"] = ""
-	for _, key := range unmappedKeys {
-		ghosttyThemeConfig["# "+key] = themeFile[key]
+	if !filepath.IsAbs(includePath) {
+		baseDir := filepath.Dir(p.configPath)
+		if !strings.HasPrefix(source, "env:") {
+			baseDir = filepath.Dir(source)
+		}
+		includePath = filepath.Join(baseDir, includePath)
 	}
-
-	// return the ghostty theme config
-	return ghosttyThemeConfig
+	return filepath.Clean(includePath)
 }
 
-// alacritty
-// Implement the Parse method
-func (a *AlacrittyParser) Parse(SourceFilepath string) (map[string]string, error) {
-	configDir := filepath.Dir(a.configPath)
-	// handle recursion
-	if a.recursionDepth > a.maxRecursionDepth {
-		return nil, fmt.Errorf("maximum recursion depth reached")
-	}
-	a.recursionDepth++
-	defer func() { a.recursionDepth-- }()
+func (p *KittyParser) ConvertToGhostty(kittyConfig *Document) (*Document, error) {
+	ghosttyConfig := NewDocument()
+	var unmapped []unmappedSetting
+	p.report = NewReport("kitty")
+	p.themes = nil
 
-	// Add your implementation here
-	file, err := os.Open(SourceFilepath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open config file: %w", err)
-	}
-	defer file.Close()
+	// a themes/ include becomes a theme name, so the colors it pulled in are skipped
+	var themeFile, themeName string
 
-	config := make(map[string]string)
-	var fullThemePath string
-	scanner := bufio.NewScanner(file)
-	currentSection := ""
+	// kitty applies the auto themes over the colors in the config, which
+	// become the theme for the appearances without an auto theme and fill in
+	// the colors the auto themes don't set
+	autoThemes := p.findAutoThemes()
+	colorConfig := ghosttyConfig
+	if len(autoThemes) > 0 {
+		colorConfig = NewDocument()
+	}
 
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	// map lines depend on kitty_mod, so their transforms are built per config
+	bindingCodex := newKittyBindings(kittyConfig).codex()
 
-		// skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
+	for _, entry := range kittyConfig.Entries {
+		value := entry.Value()
+		if themeFile != "" && entry.Source == themeFile {
+			p.report.add(entry, StatusTransformed, []string{"theme"}, "replaced by theme = "+themeName)
 			continue
 		}
 
-		// handle the theme file
-		if strings.Contains(line, "/theme/") ||
-			strings.Contains(line, "themes") &&
-				strings.HasSuffix(line, `.toml",`) {
-
-			// Clean and normalize path
-			themePath := strings.Trim(line, `"',`)
-
-			// Get home directory
-			homeDir, err := os.UserHomeDir()
+		// colors from current-theme.conf and other included files are
+		// converted like any other setting
+		settings, handled, err := convertEntry(kittyToGhosttyCodex, ghosttyConfig, entry)
+		if !handled {
+			settings, handled, err = convertEntry(kittyToGhosttyThemeCodex, colorConfig, entry)
+		}
+		if !handled {
+			settings, handled, err = outOfRangeColor(colorConfig, entry)
+		}
+		if !handled {
+			settings, handled, err = convertEntry(bindingCodex, ghosttyConfig, entry)
+		}
+		if handled {
 			if err != nil {
-				fmt.Printf(colorWarning("Could not get home directory: %v\n"), err)
-				continue
+				unmapped = append(unmapped, unmappedSetting{entry, err.Error()})
 			}
-
-			// Handle ~ expansion and relative paths
-			if strings.HasPrefix(themePath, "~") {
-				themePath = filepath.Join(homeDir, themePath[1:])
-			} else if strings.HasPrefix(themePath, ".") ||
-				strings.HasPrefix(themePath, "/") {
-				themePath = filepath.Join(configDir, themePath)
-			} else if !filepath.IsAbs(themePath) {
-				themePath = filepath.Join(homeDir, themePath)
-			}
-
-			fullThemePath = filepath.Clean(themePath)
-			continue
-		}
-
-		// because toml, handle section headers
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			currentSection = line[1 : len(line)-1]
+			p.report.addConverted(entry, settings, err)
 			continue
 		}
 
-		// parse the key value pairs
-		if parts := strings.SplitN(line, "=", 2); len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-
-			// remove matching quotes
-			if (strings.HasPrefix(value, "\"") && strings.HasSuffix(value, "\"")) ||
-				(strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'")) {
-				value = value[1 : len(value)-1]
+		switch entry.Key {
+		case "include":
+			// handle theme conversion from kitty to ghostty, only for themes
+			// ghostty ships, the colors of other themes are converted
+			if strings.Contains(value, "themes/") {
+				// split the path to get the theme name
+				parts := strings.Split(value, "/")
+				// get the last part and remove the extension
+				name := strings.TrimSuffix(parts[len(parts)-1], ".conf")
+				if ghosttyName, ok := ghostty.FindTheme(name); ok {
+					themeName = ghosttyName
+					ghosttyConfig.Set("theme", themeName)
+					themeFile = p.resolveIncludePath(value, entry.Source)
+					p.report.add(entry, StatusTransformed, []string{"theme"}, "")
+					continue
+				}
 			}
-
-			// handle nested keys
-			if currentSection != "" {
-				key = a.normaliseAlacrittyKey(currentSection + "." + key)
+			// a theme picked with the themes kitten, eg current-theme.conf
+			includePath := p.resolveIncludePath(value, entry.Source)
+			if name, ok := ghostty.FindTheme(p.recogniseTheme(includePath)); ok {
+				themeName = name
+				ghosttyConfig.Set("theme", themeName)
+				themeFile = includePath
+				p.report.add(entry, StatusTransformed, []string{"theme"}, "recognised as the ghostty "+name+" theme")
+				continue
 			}
-
-			// normalise the value
-			value = a.normaliseAlacrittyValue(value)
-
-			config[key] = value
-		}
-	}
-
-	// add the theme file values if there was one
-	if fullThemePath != "" && !a.isParsingTheme {
-		a.isParsingTheme = true
-		// check that the file exists
-		if _, err := os.Stat(fullThemePath); err != nil {
-			fmt.Printf(colorWarning("Theme file not found: %v\n"), err)
-		}
-		themeConfig, err := a.Parse(fullThemePath)
-		if err != nil {
-			fmt.Printf(colorWarning("Failed to parse theme file: %v\n"), err)
-		} else {
-			for key, value := range themeConfig {
-				// only add colors
-				if strings.Contains(key, "colors") {
-					config[key] = value
-				}
-
+			// the included settings are reported on their own
+			if _, err := os.Stat(includePath); err != nil {
+				p.report.add(entry, StatusUnsupported, nil, "could not read "+includePath)
+			} else {
+				p.report.add(entry, StatusMapped, nil, "the included settings are converted in its place")
 			}
+			continue
+		case "globinclude":
+			p.report.add(entry, StatusMapped, nil, "the settings of the matching files are converted in its place")
+			continue
+		case "envinclude":
+			p.report.add(entry, StatusMapped, nil, "the settings from the environment are converted in its place")
+			continue
+		case "geninclude":
+			p.report.add(entry, StatusUnsupported, nil, "ghostty can't run a program to generate its config")
+			continue
+		case "kitty_mod", "action_alias", "kitten_alias":
+			p.report.add(entry, StatusTransformed, []string{"keybind"}, "expanded in the keybinds that use it")
+			continue
 		}
-		a.isParsingTheme = false
-	}
 
-	return config, nil
-}
-
-// normalise alaraity keys
-func (a *AlacrittyParser) normaliseAlacrittyKey(key string) string {
-	// replace dots with underscores
-	key = strings.ReplaceAll(key, ".", "_")
-
-	// remove complex keys
-	if strings.Contains(key, "{") {
-		key = strings.Split(key, "{")[0]
+		// handle unmapped keys
+		unmapped = append(unmapped, unmappedSetting{entry: entry})
+		p.report.add(entry, StatusUnsupported, nil, "no ghostty equivalent")
 	}
 
-	if strings.Contains(key, "[") {
-		key = strings.Split(key, "[")[0]
-	}
-	return key
-}
+	mergeFlags(ghosttyConfig, "bell-features")
+	dropRepeatedValues(ghosttyConfig, "font-feature")
+	mergeCodepointMaps(ghosttyConfig)
 
-// normalise alacritty values
-func (a *AlacrittyParser) normaliseAlacrittyValue(value string) string {
-
-	// handle fonts
-	if strings.Contains(value, "family =") {
-		if parts := strings.Split(value, "family ="); len(parts) > 1 {
-			family := strings.TrimSpace(parts[1])
-			// get the font name
-			if idx := strings.Index(family, "\""); idx >= 0 {
-				if endIdx := strings.Index(family[idx+1:], "\""); endIdx >= 0 {
-					return family[idx+1 : idx+1+endIdx]
-				}
-			}
+	if len(autoThemes) > 0 {
+		if theme := p.convertAutoThemes(autoThemes, themeName, colorConfig); theme != "" {
+			ghosttyConfig.Set("theme", theme)
 		}
 	}
 
-	// handle window padding
-	if strings.Contains(value, "x") {
-		if parts := strings.Split(value, "x ="); len(parts) > 1 {
-			if len(parts) > 1 {
-				xValue := strings.Split(strings.TrimSpace(parts[1]), ",")[0]
-				return xValue
-			}
-		}
-	}
-
-	// make Always or always or on to true
-	if strings.ToLower(value) == "always" || strings.ToLower(value) == "on" || strings.ToLower(value) == "full" {
-		return "true"
-	}
-
-	// neveer to false
-	if strings.ToLower(value) == "never" || strings.ToLower(value) == "off" {
-		return "false"
-	}
-
-	// OnlyCopy
-	if strings.ToLower(value) == "onlycopy" {
-		return "true"
-	}
+	// add unmapped keys to the ghostty config but comment them out
+	appendUnmapped(ghosttyConfig, unmapped)
 
-	return value
+	return ghosttyConfig, nil
 }
 
-// Implement the Write method
-func (a *AlacrittyParser) Write(filepath string, config map[string]string) error {
-	// Create directory if it doesn't exist
-	dir := path.Dir(filepath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
-	}
-
-	//  before writing the file make a backup if ther is already one
-	if _, err := os.Stat(filepath); err == nil {
-		backupPath := filepath + ".bak"
-		// Remove existing backup if it exists
-		if _, err := os.Stat(backupPath); err == nil {
-			if err := os.Remove(backupPath); err != nil {
-				return fmt.Errorf("failed to remove existing backup: %w", err)
-			}
+// add unmapped entries commented out under a heading, with the reason they
+// could not be converted when there is one
+func appendUnmapped(ghosttyConfig *Document, unmapped []unmappedSetting) {
+	for i, setting := range unmapped {
+		disabled := &Entry{
+			Key:      setting.entry.Key,
+			Values:   setting.entry.Values,
+			Source:   setting.entry.Source,
+			Line:     setting.entry.Line,
+			Disabled: true,
 		}
-
-		// Create new backup
-		if err := os.Rename(filepath, backupPath); err != nil {
-			return fmt.Errorf("failed to create backup: %w", err)
+		if i == 0 {
+			disabled.Comments = []string{"", "Unmapped settings"}
+		}
+		if setting.reason != "" {
+			disabled.Comments = append(disabled.Comments, setting.reason)
 		}
+		ghosttyConfig.Append(disabled)
 	}
+}
 
-	// create the file
-	file, err := os.Create(filepath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
+// convert the colors of a kitty theme file, adding them to report
+func convertKittyTheme(themeFile *Document, report *Report) *Document {
 
-	// sort the keys alphabetically
-	keys := sortKeysAlphabetically(config)
+	ghosttyThemeConfig := NewDocument()
+	var unmapped []unmappedSetting
 
-	// write to the file
-	writer := bufio.NewWriter(file)
-	for _, key := range keys {
-		_, err := writer.WriteString(fmt.Sprintf("%s = %s\n", key, config[key]))
+	for _, entry := range themeFile.Entries {
+		settings, handled, err := convertEntry(kittyToGhosttyThemeCodex, ghosttyThemeConfig, entry)
+		if !handled {
+			settings, handled, err = outOfRangeColor(ghosttyThemeConfig, entry)
+		}
 		if err != nil {
-			return err
+			unmapped = append(unmapped, unmappedSetting{entry, err.Error()})
+		} else if !handled {
+			// hanndle unmapped keys
+			unmapped = append(unmapped, unmappedSetting{entry: entry})
+			report.add(entry, StatusUnsupported, nil, "not a theme color")
+			continue
 		}
+		report.addConverted(entry, settings, err)
 	}
 
-	return writer.Flush()
-}
+	// add unmapped keys to the ghostty theme config but comment them out
+	appendUnmapped(ghosttyThemeConfig, unmapped)
 
-func (a *AlacrittyParser) ConvertToGhostty(config map[string]string) (map[string]string, error) {
-	ghosttyConfig := make(map[string]string)
+	// return the ghostty theme config
+	return ghosttyThemeConfig
+}
 
-	for alacrittyKey, value := range config {
-		if ghosttyKey, exists := alacrittyToGhostty[alacrittyKey]; exists {
-			ghosttyConfig[ghosttyKey] = value
-		} else {
-			// handle unmapped keys
-			ghosttyConfig["# "+alacrittyKey] = value
-		}
-	}
+var kittyToGhosttyThemeCodex = withKittyPalette(map[string]valueTransform{
+	// Standard colors
+	"background": color("background"),
+	"foreground": color("foreground"),
+	// none makes kitty draw the cursor and selections in reverse video
+	"cursor": specialColor(map[string][]setting{
+		"none": {{key: "cursor-color", value: cellForeground}, {key: "cursor-text", value: cellBackground}},
+	}, color("cursor-color")),
+	"cursor_text_color": specialColor(map[string][]setting{
+		"background": {{key: "cursor-text", value: cellBackground}},
+	}, color("cursor-text")),
+	"selection_background": specialColor(map[string][]setting{
+		"none": {{key: "selection-invert-fg-bg", value: "true", approx: "ghostty swaps the foreground and background of the whole selection"}},
+	}, color("selection-background")),
+	"selection_foreground": specialColor(map[string][]setting{
+		"none": {{key: "selection-foreground", value: cellForeground}},
+	}, color("selection-foreground")),
+})
+
+// withKittyPalette adds color0 to color255 to codex
+func withKittyPalette(codex map[string]valueTransform) map[string]valueTransform {
+	for index := range paletteSize {
+		codex[fmt.Sprintf("color%d", index)] = paletteColor(index)
+	}
+	return codex
+}
 
-	// if alacritty has a blur set it to default 10 in ghostty
-	for key, value := range config {
-		// if key window_blur is value true set it to 10
-		if key == "window_blur" && value == "true" {
-			ghosttyConfig["background-blur-radius"] = "10"
-		}
+// kittyPaletteIndex returns N for a colorN key
+func kittyPaletteIndex(key string) (int, bool) {
+	digits, ok := strings.CutPrefix(key, "color")
+	if !ok || digits == "" {
+		return 0, false
 	}
-
-	return ghosttyConfig, nil
+	index, err := strconv.Atoi(digits)
+	return index, err == nil
 }
 
-var kittyToGhosttyThemeCodex = map[string]string{
-	// Standard colors
-	"background":           "background",
-	"foreground":           "foreground",
-	"cursor":               "cursor-color",
-	"selection_background": "selection-background",
-	"selection_foreground": "selection-foreground",
-
-	// Color palette
-	"color0":  "palette = 0=",
-	"color1":  "palette = 1=",
-	"color2":  "palette = 2=",
-	"color3":  "palette = 3=",
-	"color4":  "palette = 4=",
-	"color5":  "palette = 5=",
-	"color6":  "palette = 6=",
-	"color7":  "palette = 7=",
-	"color8":  "palette = 8=",
-	"color9":  "palette = 9=",
-	"color10": "palette = 10=",
-	"color11": "palette = 11=",
-	"color12": "palette = 12=",
-	"color13": "palette = 13=",
-	"color14": "palette = 14=",
-	"color15": "palette = 15=",
+// outOfRangeColor converts colorN keys past the end of the palette, which
+// have no entry in the theme codex, so they are reported with the reason
+func outOfRangeColor(ghosttyConfig *Document, entry *Entry) ([]setting, bool, error) {
+	index, ok := kittyPaletteIndex(entry.Key)
+	if !ok {
+		return nil, false, nil
+	}
+	return convertEntry(map[string]valueTransform{entry.Key: paletteColor(index)}, ghosttyConfig, entry)
 }
 
 // create the mapping functions
-var kittyToGhosttyCodex = map[string]string{
+var kittyToGhosttyCodex = map[string]valueTransform{
 	// Font settings
-	"font_family":      "font-family",
-	"bold_font":        "font-family-bold",
-	"italic_font":      "font-family-italic",
-	"bold_italic_font": "font-family-bold-italic",
-	"font_size":        "font-size",
+	"font_family":       kittyFont(""),
+	"bold_font":         unlessDefault("auto", kittyFont("-bold")),
+	"italic_font":       unlessDefault("auto", kittyFont("-italic")),
+	"bold_italic_font":  unlessDefault("auto", kittyFont("-bold-italic")),
+	"font_features":     kittyFontFeatures,
+	"disable_ligatures": kittyDisableLigatures,
+	"modify_font":       kittyModifyFont,
+	"font_size":         number("font-size"),
+	"symbol_map":        kittySymbolMap,
+	"narrow_symbols":    kittyNarrowSymbols,
 
 	// Window settings
-	"window_padding":           "window-padding",
-	"remember_window_size":     "window-save-state",
-	"initial_window_width":     "window-width",
-	"initial_window_height":    "window-height",
-	"window_resize_step_cells": "window-resize-step",
-	"window_decorations":       "window-decoration",
-	"window_opacity":           "background-opacity",
-	"background_opacity":       "background-opacity",
+	"window_padding_width": kittyPadding,
+	"remember_window_size": enum("window-save-state", map[string]string{
+		"yes": "",
+		"no":  "never",
+	}),
+	"initial_window_width":     cells("window-width"),
+	"initial_window_height":    cells("window-height"),
+	"window_resize_step_cells": unsupported("ghostty has no keyboard resize step"),
+	"hide_window_decorations": enumSettings(map[string][]setting{
+		"yes":                  {{key: "window-decoration", value: "false"}},
+		"no":                   nil,
+		"titlebar-only":        {{key: "macos-titlebar-style", value: "hidden", approx: "ghostty can only hide the titlebar on macOS"}},
+		"titlebar-and-corners": {{key: "macos-titlebar-style", value: "hidden", approx: "ghostty can only hide the titlebar on macOS"}},
+	}),
+	"background_opacity": number("background-opacity"),
 
 	// scrolling
-	"scrolling_multiplier": "mouse-scroll-multiplier",
+	"wheel_scroll_multiplier": number("mouse-scroll-multiplier"),
 
-	// mouse
-	"mouse_hide_when_typing": "mouse-hide-while-typing",
+	// mouse, kitty hides the pointer after a delay in seconds, 0 disables it
+	"mouse_hide_wait": kittyMouseHideWait,
 
 	// Terminal behavior
-	"selection_save_to_clipboard": "copy-on-select",
+	"copy_on_select": enum("copy-on-select", map[string]string{
+		"no":        "false",
+		"yes":       "clipboard",
+		"clipboard": "clipboard",
+	}),
 
 	// Cursor
-	"cursor_shape":          "cursor-style",
-	"cursor_beam_thickness": "cursor-beam-width",
-	"cursor_blink_interval": "cursor-blink-interval",
-	"colors_cursor_cursor":  "cursor-color",
-	"colors_cursor_text":    "cursor-text-color",
+	"cursor_shape": enum("cursor-style", map[string]string{
+		"block":     "block",
+		"beam":      "bar",
+		"underline": "underline",
+	}),
+	"cursor_beam_thickness": approximately("ghostty adjusts the thickness of every cursor style, not just the beam",
+		number("adjust-cursor-thickness")),
+	"cursor_blink_interval": nonZero("cursor-style-blink"),
 
 	// MacOS specific
-	"macos_option_as_alt":    "macos-option-as-alt",
-	"macos_titlebar_color":   "macos-titlebar-style",
-	"macos_window_resizable": "window-resize-from-any-edge",
+	"macos_option_as_alt": enum("macos-option-as-alt", map[string]string{
+		"no":    "false",
+		"yes":   "true",
+		"both":  "true",
+		"left":  "left",
+		"right": "right",
+	}),
+	"macos_titlebar_color": enum("macos-titlebar-style", map[string]string{
+		"system":     "native",
+		"background": "transparent",
+	}),
+	"macos_window_resizable": unsupported("ghostty windows are always resizable"),
 
 	// Shell integration
-	"shell":             "command",
-	"working_directory": "working-directory",
+	"shell": unlessDefault(".", rename("command")),
 
-	// Basic colors
-	"colors_primary_background": "background",
-	"colors_primary_foreground": "foreground",
+	// Additional mappings from config
+	"tab_bar_edge": enum("gtk-tabs-location", map[string]string{
+		"top":    "top",
+		"bottom": "bottom",
+	}),
+	"scrollback_lines":     scrollbackLines("scrollback-limit"),
+	"repaint_delay":        unsupported("ghostty has no repaint delay, rendering follows window-vsync"),
+	"input_delay":          unsupported("ghostty has no input delay"),
+	"sync_to_monitor":      boolean("window-vsync"),
+	"window_logo_position": unsupported("ghostty has no window logo"),
+
+	// bell, combined into a single bell-features line
+	"enable_audio_bell":    bellSwitch("system"),
+	"window_alert_on_bell": bellSwitch("attention"),
+	"visual_bell_duration": kittyVisualBell,
+	"visual_bell_color":    unlessDefault("none", unsupported("ghostty's bell border has no color setting")),
+	"bell_on_tab":          kittyBellOnTab,
+	"command_on_bell":      unlessDefault("none", unsupported("ghostty can't run a command on the bell")),
+	"placement_strategy": approximately("ghostty balances the padding instead of placing the grid",
+		enum("window-padding-balance", map[string]string{
+			"center":   "true",
+			"top-left": "false",
+		})),
+}
 
-	// Selection colors
-	"colors_selection_background": "selection-background",
-	"colors_selection_text":       "selection-foreground",
+// kittyMouseHideWait converts mouse_hide_wait. A negative wait hides the
+// pointer as soon as you type, which is what ghostty does, a positive one
+// hides it once the mouse has been idle that long.
+func kittyMouseHideWait(value string) ([]setting, error) {
+	wait, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return nil, unrepresentable(value, "expected a number of seconds")
+	}
+	switch {
+	case wait < 0:
+		return []setting{{key: "mouse-hide-while-typing", value: "true"}}, nil
+	case wait > 0:
+		return []setting{{key: "mouse-hide-while-typing", value: "true", approx: "ghostty hides the pointer while typing, not after the mouse is idle"}}, nil
+	}
+	return []setting{{key: "mouse-hide-while-typing", value: "false"}}, nil
+}
 
-	// Additional mappings from config
-	"tab_bar_edge":           "gtk-tabs-location",
-	"scrollback_lines":       "scrollback-limit",
-	"repaint_delay":          "window-vsync",
-	"input_delay":            "window-vsync",
-	"window_alert_on_bell":   "desktop-notifications",
-	"window_logo_position":   "resize-overlay-position",
-	"window_padding_balance": "window-padding-balance",
-	"placement_strategy":     "window-theme",
+// kitty padding takes one to four values in css order, ghostty wants left,right
+// and top,bottom pairs
+func kittyPadding(value string) ([]setting, error) {
+	fields := strings.Fields(value)
+	for _, field := range fields {
+		if _, err := strconv.ParseFloat(field, 64); err != nil {
+			return nil, unrepresentable(value, "expected padding in points")
+		}
+	}
+
+	var top, right, bottom, left string
+	switch len(fields) {
+	case 1:
+		top, right, bottom, left = fields[0], fields[0], fields[0], fields[0]
+	case 2:
+		top, right, bottom, left = fields[0], fields[1], fields[0], fields[1]
+	case 3:
+		top, right, bottom, left = fields[0], fields[1], fields[2], fields[1]
+	case 4:
+		top, right, bottom, left = fields[0], fields[1], fields[2], fields[3]
+	default:
+		return nil, unrepresentable(value, "expected one to four padding values")
+	}
+
+	return []setting{
+		{key: "window-padding-x", value: paddingPair(left, right)},
+		{key: "window-padding-y", value: paddingPair(top, bottom)},
+	}, nil
 }
 
-var alacrittyToGhostty = map[string]string{
-	// Font Settings
-	"font_normal":      "font-family",
-	"font_size":        "font-size",
-	"font_italic":      "font-family-italic",
-	"font_bold_italic": "font-family-bold-italic",
-	"font_bold":        "font-family-bold",
-
-	// Cursor Settings
-	"cursor-style":                  "cursor-style",
-	"cursor_vi_mode_style_blinking": "cursor-style-blink",
-	"cursor_text":                   "cursor-text",
-	"cursor_color":                  "cursor-color",
-	"cursor_blink":                  "cursor-style-blink",
-	"colors_cursor_cursor":          "cursor-color",
-	"cursor_vi_mode_style_shape":    "cursor-style",
-	"cursor_style_blinking":         "cursor-style-blink",
-	"colors_cursor_text":            "cursor-text",
-
-	// Colors
-	"colors_primary_background":   "background",
-	"colors_primary_foreground":   "foreground",
-	"colors_selection_foreground": "selection-foreground",
-	"colors_selection_background": "selection-background",
-
-	// Window Layout
-	"window_padding":       "window-padding-x",
-	"window_padding_color": "window-padding-color",
-	"window_title":         "title",
-	"window_decorations":   "window-decoration",
-
-	// Window Behavior
-	"window_inherit_working_directory": "window-inherit-working-directory",
-	"window_inherit_font_size":         "window-inherit-font-size",
-	"window_save_state":                "window-save-state",
-	"window_step_resize":               "window-step-resize",
-	"window_new_tab_position":          "window-new-tab-position",
-	"window_opacity":                   "background-opacity",
-
-	// Scrollback & Mouse
-	"scrolling_history":      "scrollback-limit",
-	"keyboard_CopySelection": "copy-on-select",
-
-	// Clipboard Handling
-	"terminal_osc52": "clipboard-read",
-
-	// Normal colors (0-7)
-	"colors_normal_black":   "palette = 0",
-	"colors_normal_red":     "palette = 1",
-	"colors_normal_green":   "palette = 2",
-	"colors_normal_yellow":  "palette = 3",
-	"colors_normal_blue":    "palette = 4",
-	"colors_normal_magenta": "palette = 5",
-	"colors_normal_cyan":    "palette = 6",
-	"colors_normal_white":   "palette = 7",
-
-	// Bright colors (8-15)
-	"colors_bright_black":   "palette = 8",
-	"colors_bright_red":     "palette = 9",
-	"colors_bright_green":   "palette = 10",
-	"colors_bright_yellow":  "palette = 11",
-	"colors_bright_blue":    "palette = 12",
-	"colors_bright_magenta": "palette = 13",
-	"colors_bright_cyan":    "palette = 14",
-	"colors_bright_white":   "palette = 15",
+// a single value when both sides match, eg 4 or 2,8
+func paddingPair(first, second string) string {
+	if first == second {
+		return first
+	}
+	return first + "," + second
 }
@@ -0,0 +1,125 @@
+package parser
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestKittyMouseHideWait(t *testing.T) {
+	tests := []struct {
+		value      string
+		want       string
+		wantApprox bool
+	}{
+		{"-1", "true", false},
+		{"0", "false", false},
+		{"3.0", "true", true},
+	}
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			settings, err := kittyMouseHideWait(test.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(settings) != 1 || settings[0].key != "mouse-hide-while-typing" || settings[0].value != test.want {
+				t.Fatalf("kittyMouseHideWait(%q) = %v, want mouse-hide-while-typing = %s", test.value, settings, test.want)
+			}
+			if (settings[0].approx != "") != test.wantApprox {
+				t.Errorf("kittyMouseHideWait(%q) approximation = %q", test.value, settings[0].approx)
+			}
+		})
+	}
+	if _, err := kittyMouseHideWait("soon"); err == nil {
+		t.Errorf("kittyMouseHideWait(soon) succeeded, want an error")
+	}
+}
+
+func TestKittyIncludes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		env   map[string]string
+		want  []string
+	}{
+		{
+			"include in place",
+			map[string]string{"kitty.conf": "font_size 10\ninclude a.conf\ncursor_shape beam\n", "a.conf": "font_size 11\n"},
+			nil,
+			[]string{"font_size 10", "font_size 11", "cursor_shape beam"},
+		},
+		{
+			"relative to the including file",
+			map[string]string{"kitty.conf": "include sub/a.conf\n", "sub/a.conf": "include b.conf\n", "sub/b.conf": "font_size 11\n"},
+			nil,
+			[]string{"font_size 11"},
+		},
+		{
+			"environment variables in the path",
+			map[string]string{"kitty.conf": "include ${THEME}.conf\n", "dark.conf": "background #000000\n"},
+			map[string]string{"THEME": "dark"},
+			[]string{"background #000000"},
+		},
+		{
+			"globinclude in name order",
+			map[string]string{"kitty.conf": "globinclude conf.d/*.conf\n", "conf.d/b.conf": "font_size 12\n", "conf.d/a.conf": "font_size 11\n", "conf.d/c.txt": "font_size 13\n"},
+			nil,
+			[]string{"font_size 11", "font_size 12"},
+		},
+		{
+			"envinclude",
+			map[string]string{"kitty.conf": "envinclude KITTY_TEST_*\nfont_size 14\n"},
+			map[string]string{"KITTY_TEST_B": "cursor_shape beam", "KITTY_TEST_A": "font_size 11\nfont_family Hack"},
+			[]string{"font_size 11", "font_family Hack", "cursor_shape beam", "font_size 14"},
+		},
+		{
+			"envinclude includes relative to the config",
+			map[string]string{"kitty.conf": "envinclude KITTY_TEST_INCLUDE\n", "a.conf": "font_size 11\n"},
+			map[string]string{"KITTY_TEST_INCLUDE": "include a.conf"},
+			[]string{"font_size 11"},
+		},
+		{
+			"cycle",
+			map[string]string{"kitty.conf": "include a.conf\n", "a.conf": "font_size 11\ninclude kitty.conf\n"},
+			nil,
+			[]string{"font_size 11"},
+		},
+		{
+			"missing file",
+			map[string]string{"kitty.conf": "include missing.conf\nfont_size 11\n"},
+			nil,
+			[]string{"font_size 11"},
+		},
+		{
+			"geninclude is skipped",
+			map[string]string{"kitty.conf": "geninclude gen.py\nfont_size 11\n"},
+			nil,
+			[]string{"font_size 11"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				t.Setenv(name, value)
+			}
+			dir := writeFiles(t, test.files)
+			configPath := filepath.Join(dir, "kitty.conf")
+			config, err := NewKittyParser(configPath).Parse(configPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, entry := range config.Entries {
+				switch entry.Key {
+				case "include", "globinclude", "envinclude", "geninclude":
+					continue
+				}
+				got = append(got, entry.Key+" "+entry.Value())
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("entries = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Status is the outcome of converting a single source setting
+type Status string
+
+const (
+	StatusMapped           Status = "mapped"
+	StatusTransformed      Status = "transformed"
+	StatusApproximated     Status = "approximated"
+	StatusUnsupported      Status = "unsupported"
+	StatusIgnoredAsDefault Status = "ignored-as-default"
+)
+
+// the order statuses are summarised in
+var reportStatuses = []Status{
+	StatusMapped,
+	StatusTransformed,
+	StatusApproximated,
+	StatusUnsupported,
+	StatusIgnoredAsDefault,
+}
+
+// ReportItem describes what happened to one source setting
+type ReportItem struct {
+	Key         string   `json:"key"`
+	Value       string   `json:"value"`
+	Source      string   `json:"source"`
+	Line        int      `json:"line"`
+	Status      Status   `json:"status"`
+	GhosttyKeys []string `json:"ghostty_keys,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+}
+
+// Origin is the file:line the setting came from
+func (i ReportItem) Origin() string {
+	if i.Line == 0 {
+		return i.Source
+	}
+	return fmt.Sprintf("%s:%d", i.Source, i.Line)
+}
+
+// Report lists every source setting of a conversion with its status
+type Report struct {
+	Terminal string       `json:"terminal"`
+	Items    []ReportItem `json:"items"`
+}
+
+// constructor for an empty report
+func NewReport(terminal string) *Report {
+	return &Report{Terminal: terminal, Items: []ReportItem{}}
+}
+
+// add records the outcome for entry
+func (r *Report) add(entry *Entry, status Status, ghosttyKeys []string, reason string) {
+	r.Items = append(r.Items, ReportItem{
+		Key:         entry.Key,
+		Value:       strings.Join(entry.Values, ", "),
+		Source:      entry.Source,
+		Line:        entry.Line,
+		Status:      status,
+		GhosttyKeys: ghosttyKeys,
+		Reason:      reason,
+	})
+}
+
+// addConverted works out the status of an entry from the settings its
+// transform produced
+func (r *Report) addConverted(entry *Entry, settings []setting, err error) {
+	if err != nil {
+		reason := err.Error()
+		var unrepresentable *unrepresentableError
+		if errors.As(err, &unrepresentable) && unrepresentable.value == "" {
+			reason = unrepresentable.reason
+		}
+		r.add(entry, StatusUnsupported, nil, reason)
+		return
+	}
+
+	if len(settings) == 0 {
+		r.add(entry, StatusIgnoredAsDefault, nil, "same as the ghostty default")
+		return
+	}
+
+	var keys, approximations []string
+	for _, s := range settings {
+		if !slices.Contains(keys, s.key) {
+			keys = append(keys, s.key)
+		}
+		if s.approx != "" && !slices.Contains(approximations, s.approx) {
+			approximations = append(approximations, s.approx)
+		}
+	}
+
+	switch {
+	case len(approximations) > 0:
+		r.add(entry, StatusApproximated, keys, strings.Join(approximations, "; "))
+	case len(settings) == 1 && settings[0].value == entry.Value():
+		r.add(entry, StatusMapped, keys, "")
+	default:
+		r.add(entry, StatusTransformed, keys, "")
+	}
+}
+
+// Counts returns the number of items per status
+func (r *Report) Counts() map[Status]int {
+	counts := make(map[Status]int, len(reportStatuses))
+	for _, item := range r.Items {
+		counts[item.Status]++
+	}
+	return counts
+}
+
+func (r *Report) summary() string {
+	counts := r.Counts()
+	parts := make([]string, 0, len(reportStatuses))
+	for _, status := range reportStatuses {
+		parts = append(parts, fmt.Sprintf("%s %d", status, counts[status]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Text renders the report as aligned columns for the terminal
+func (r *Report) Text() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("Conversion report (%s -> ghostty)\n", r.Terminal))
+	builder.WriteString(r.summary() + "\n\n")
+
+	writer := tabwriter.NewWriter(&builder, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "STATUS\tSETTING\tGHOSTTY\tORIGIN\tREASON")
+	for _, item := range r.Items {
+		ghosttyKeys := strings.Join(item.GhosttyKeys, ", ")
+		if ghosttyKeys == "" {
+			ghosttyKeys = "-"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", item.Status, item.Key, ghosttyKeys, item.Origin(), item.Reason)
+	}
+	writer.Flush()
+	return builder.String()
+}
+
+// JSON renders the report for other tools
+func (r *Report) JSON() (string, error) {
+	report := struct {
+		Terminal string         `json:"terminal"`
+		Summary  map[Status]int `json:"summary"`
+		Items    []ReportItem   `json:"items"`
+	}{r.Terminal, r.Counts(), r.Items}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// Markdown renders the report as a table for tickets and docs
+func (r *Report) Markdown() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## Conversion report (%s → ghostty)\n\n", r.Terminal))
+	builder.WriteString(r.summary() + "\n\n")
+	builder.WriteString("| Status | Setting | Value | Ghostty | Origin | Reason |\n")
+	builder.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+	for _, item := range r.Items {
+		builder.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s | %s | %s |\n",
+			item.Status,
+			item.Key,
+			markdownCode(item.Value),
+			markdownCode(strings.Join(item.GhosttyKeys, ", ")),
+			markdownEscape(item.Origin()),
+			markdownEscape(item.Reason),
+		))
+	}
+	return builder.String()
+}
+
+func markdownEscape(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+func markdownCode(text string) string {
+	if text == "" {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(markdownEscape(text), "`", "'") + "`"
+}
+
+// CheckReportFormat returns an error for formats Render does not know
+func CheckReportFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "text", "txt", "json", "markdown", "md":
+		return nil
+	}
+	return fmt.Errorf("unsupported report format: %s (use text, json or markdown)", format)
+}
+
+// Render formats the report as text, json or markdown
+func (r *Report) Render(format string) (string, error) {
+	if err := CheckReportFormat(format); err != nil {
+		return "", err
+	}
+	switch strings.ToLower(format) {
+	case "json":
+		return r.JSON()
+	case "markdown", "md":
+		return r.Markdown(), nil
+	default:
+		return r.Text(), nil
+	}
+}
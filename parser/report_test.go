@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestReportAddConverted(t *testing.T) {
+	tests := []struct {
+		name       string
+		key, value string
+		transform  valueTransform
+		status     Status
+		keys       []string
+		reason     string
+	}{
+		{"same value", "font_size", "12", kittyToGhosttyCodex["font_size"], StatusMapped, []string{"font-size"}, ""},
+		{"changed value", "mouse_hide_wait", "-1", kittyToGhosttyCodex["mouse_hide_wait"], StatusTransformed, []string{"mouse-hide-while-typing"}, ""},
+		{"several settings", "disable_ligatures", "always", kittyToGhosttyCodex["disable_ligatures"], StatusTransformed, []string{"font-feature"}, ""},
+		{"approximation", "mouse_hide_wait", "3.0", kittyToGhosttyCodex["mouse_hide_wait"], StatusApproximated, []string{"mouse-hide-while-typing"},
+			"ghostty hides the pointer while typing, not after the mouse is idle"},
+		{"no settings", "bell.animation", "Linear", alacrittyToGhostty["bell.animation"], StatusIgnoredAsDefault, nil, "same as the ghostty default"},
+		{"unrepresentable", "bell.animation", "EaseOut", alacrittyToGhostty["bell.animation"], StatusUnsupported, nil, "ghostty doesn't animate the bell"},
+		{"invalid value", "font_size", "big", kittyToGhosttyCodex["font_size"], StatusUnsupported, nil, ""},
+		{"approximations are joined", "x", "1", func(string) ([]setting, error) {
+			return []setting{{key: "a", value: "1", approx: "first"}, {key: "b", value: "1", approx: "second"}, {key: "b", value: "2", approx: "first"}}, nil
+		}, StatusApproximated, []string{"a", "b"}, "first; second"},
+		{"other errors", "x", "1", func(string) ([]setting, error) {
+			return nil, errors.New("broken")
+		}, StatusUnsupported, nil, "broken"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			report := NewReport("test")
+			entry := &Entry{Key: test.key, Values: []string{test.value}, Source: "config", Line: 1}
+			settings, err := test.transform(test.value)
+			report.addConverted(entry, settings, err)
+
+			if len(report.Items) != 1 {
+				t.Fatalf("got %d report items, want 1", len(report.Items))
+			}
+			item := report.Items[0]
+			if item.Status != test.status {
+				t.Errorf("status = %s, want %s", item.Status, test.status)
+			}
+			if !slices.Equal(item.GhosttyKeys, test.keys) {
+				t.Errorf("ghostty keys = %q, want %q", item.GhosttyKeys, test.keys)
+			}
+			if test.reason != "" && item.Reason != test.reason {
+				t.Errorf("reason = %q, want %q", item.Reason, test.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package parser
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Theme is a ghostty theme file produced by a conversion, the config refers
+// to it by name
+type Theme struct {
+	Name   string
+	Config *Document
+}
+
+// ThemePath is where ghostty finds the theme called name for the config at
+// configPath
+func ThemePath(configPath, name string) string {
+	return filepath.Join(filepath.Dir(configPath), "themes", name)
+}
+
+// WriteGhosttyTheme writes theme to the themes directory next to the config
+// at configPath
+func WriteGhosttyTheme(configPath string, theme Theme) error {
+	if !validThemeName(theme.Name) {
+		return fmt.Errorf("%q is not a valid theme name", theme.Name)
+	}
+	return writeGhosttyConfig(ThemePath(configPath, theme.Name), theme.Config)
+}
+
+// validThemeName is true for names that are a single file in the themes
+// directory, "." or ".." would point ThemePath at a directory
+func validThemeName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		filepath.Base(name) == name && !strings.ContainsAny(name, `/\`)
+}
+
+// the ghostty keys a theme sets
+var themeKeys = []string{
+	"background",
+	"foreground",
+	"palette",
+	"cursor-color",
+	"cursor-text",
+	"selection-foreground",
+	"selection-background",
+	"selection-invert-fg-bg",
+	"bold-color",
+}
+
+// ExtractTheme moves the colors out of config into a theme and sets theme to
+// its name. Without a name the theme is named after the file the colors came
+// from, sourcePath being the converted config. Returns nil when config has no
+// colors.
+func ExtractTheme(config *Document, sourcePath, name string) (*Theme, error) {
+	if name != "" && !validThemeName(name) {
+		return nil, fmt.Errorf("theme name %q must be a file name", name)
+	}
+
+	theme := NewDocument()
+	var sources []string
+	var entries []*Entry
+	for _, entry := range config.Entries {
+		if entry.Disabled || !slices.Contains(themeKeys, entry.Key) {
+			entries = append(entries, entry)
+			continue
+		}
+		theme.Append(entry)
+		if !slices.Contains(sources, entry.Source) {
+			sources = append(sources, entry.Source)
+		}
+	}
+	if theme.Len() == 0 {
+		return nil, nil
+	}
+	// the colors in the config override the theme, moving them would lose it
+	if current, ok := config.Get("theme"); ok {
+		return nil, fmt.Errorf("the config already uses the %s theme", current)
+	}
+	config.Entries = entries
+
+	if name == "" {
+		// colors from a single theme file are named after it, otherwise
+		// after the config
+		source := sourcePath
+		if len(sources) == 1 && sources[0] != "" {
+			source = sources[0]
+		}
+		name = kittyThemeName(source, strings.TrimSuffix(filepath.Base(source), filepath.Ext(source)))
+		if !validThemeName(name) {
+			return nil, fmt.Errorf("%s doesn't make a valid theme name, set one with --theme-name", source)
+		}
+	}
+	config.Set("theme", name)
+	return &Theme{name, theme}, nil
+}
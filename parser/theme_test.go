@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtractThemeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    string
+		wantErr bool
+	}{
+		{"Dracula", "Dracula", false},
+		{"Tokyo Night", "Tokyo Night", false},
+		{"", "kitty", false},
+		{".", "", true},
+		{"..", "", true},
+		{"../config", "", true},
+		{"themes/Dracula", "", true},
+		{`..\config`, "", true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDocument()
+			config.Add("background", "#000000", "", 0)
+			theme, err := ExtractTheme(config, filepath.Join(t.TempDir(), "kitty.conf"), test.name)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("ExtractTheme(%q) succeeded, want an error", test.name)
+				}
+				if _, ok := config.Get("background"); !ok {
+					t.Errorf("ExtractTheme(%q) removed the colors from the config", test.name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if theme.Name != test.want {
+				t.Errorf("theme name = %q, want %q", theme.Name, test.want)
+			}
+		})
+	}
+}
+
+func TestThemeHeaderName(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"## name: Tokyo Night Day", "Tokyo Night Day"},
+		{"## name: Catppuccin/Mocha", "Catppuccin-Mocha"},
+		{"## name: ..", ""},
+		{"## name: .", ""},
+		{"## name:", ""},
+		{"# no header", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			if got := themeHeaderName(strings.NewReader(test.header + "\nbackground #000000\n")); got != test.want {
+				t.Errorf("themeHeaderName() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+// a theme named after a ## name: .. header must not replace the config
+// directory
+func TestExtractThemeHeaderCantEscape(t *testing.T) {
+	dir := t.TempDir()
+	themePath := filepath.Join(dir, "current-theme.conf")
+	if err := os.WriteFile(themePath, []byte("## name: ..\nbackground #000000\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	config := NewDocument()
+	config.Add("background", "#000000", themePath, 2)
+	theme, err := ExtractTheme(config, filepath.Join(dir, "kitty.conf"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Name != "current-theme" {
+		t.Errorf("theme name = %q, want current-theme", theme.Name)
+	}
+	if err := WriteGhosttyTheme(filepath.Join(dir, "config"), Theme{"..", theme.Config}); err == nil {
+		t.Errorf("WriteGhosttyTheme wrote a theme named ..")
+	}
+}
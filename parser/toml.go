@@ -0,0 +1,875 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// TOMLError is a decode error with the position it was found at
+type TOMLError struct {
+	File    string
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *TOMLError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.File, e.Line, e.Column, e.Message)
+}
+
+// decodedTree is a decoded toml or yaml file. Tables are map[string]any,
+// arrays are []any and leaves are string, int64, float64 or bool (dates are
+// kept as strings). Lines holds the line each dotted key path was defined on.
+type decodedTree struct {
+	Root  map[string]any
+	Lines map[string]int
+}
+
+type tomlDecoder struct {
+	file      string
+	src       string
+	pos       int
+	line      int
+	lineStart int
+
+	root    map[string]any
+	current map[string]any
+	path    []string
+	lines   map[string]int
+
+	// tables created by a header or key, used to reject redefinition
+	explicit map[string]bool
+	// tables that are closed for extension (inline tables)
+	sealed map[string]bool
+}
+
+// decode a toml file, errors carry the line and column
+func decodeTOML(file string, data []byte) (*decodedTree, error) {
+	d := &tomlDecoder{
+		file:     file,
+		src:      strings.TrimPrefix(string(data), "\uFEFF"),
+		line:     1,
+		root:     map[string]any{},
+		lines:    map[string]int{},
+		explicit: map[string]bool{},
+		sealed:   map[string]bool{},
+	}
+	d.current = d.root
+
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+	return &decodedTree{Root: d.root, Lines: d.lines}, nil
+}
+
+func (d *tomlDecoder) errorf(format string, args ...any) error {
+	return d.errorAt(d.line, d.column(), format, args...)
+}
+
+func (d *tomlDecoder) errorAt(line, column int, format string, args ...any) error {
+	return &TOMLError{
+		File:    d.file,
+		Line:    line,
+		Column:  column,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// current column, counted in characters from 1
+func (d *tomlDecoder) column() int {
+	return utf8.RuneCountInString(d.src[d.lineStart:d.pos]) + 1
+}
+
+func (d *tomlDecoder) eof() bool {
+	return d.pos >= len(d.src)
+}
+
+func (d *tomlDecoder) peek() byte {
+	if d.eof() {
+		return 0
+	}
+	return d.src[d.pos]
+}
+
+func (d *tomlDecoder) advance(n int) {
+	for i := 0; i < n && !d.eof(); i++ {
+		if d.src[d.pos] == '\n' {
+			d.line++
+			d.lineStart = d.pos + 1
+		}
+		d.pos++
+	}
+}
+
+func (d *tomlDecoder) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(d.src[d.pos:], prefix)
+}
+
+// skip spaces and tabs
+func (d *tomlDecoder) skipSpace() {
+	for !d.eof() && (d.peek() == ' ' || d.peek() == '\t') {
+		d.advance(1)
+	}
+}
+
+// skip a comment up to the end of the line
+func (d *tomlDecoder) skipComment() error {
+	if d.peek() != '#' {
+		return nil
+	}
+	for !d.eof() && d.peek() != '\n' {
+		c := d.peek()
+		if c < 0x20 && c != '\t' && c != '\r' || c == 0x7f {
+			return d.errorf("control character in comment")
+		}
+		d.advance(1)
+	}
+	return nil
+}
+
+// skip whitespace, newlines and comments, used inside arrays
+func (d *tomlDecoder) skipBlank() error {
+	for !d.eof() {
+		switch d.peek() {
+		case ' ', '\t', '\r', '\n':
+			d.advance(1)
+		case '#':
+			if err := d.skipComment(); err != nil {
+				return err
+			}
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// expect the end of a line after an expression
+func (d *tomlDecoder) endOfLine() error {
+	d.skipSpace()
+	if err := d.skipComment(); err != nil {
+		return err
+	}
+	if d.eof() {
+		return nil
+	}
+	if d.hasPrefix("\r\n") {
+		d.advance(2)
+		return nil
+	}
+	if d.peek() == '\n' {
+		d.advance(1)
+		return nil
+	}
+	return d.errorf("expected end of line, found %q", d.peek())
+}
+
+func (d *tomlDecoder) parse() error {
+	for {
+		if err := d.skipBlank(); err != nil {
+			return err
+		}
+		if d.eof() {
+			return nil
+		}
+
+		var err error
+		if d.hasPrefix("[[") {
+			err = d.parseArrayTableHeader()
+		} else if d.peek() == '[' {
+			err = d.parseTableHeader()
+		} else {
+			err = d.parseKeyValue(d.current, d.path)
+		}
+		if err != nil {
+			return err
+		}
+		if err := d.endOfLine(); err != nil {
+			return err
+		}
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+// parse a dotted key eg font.normal."family"
+func (d *tomlDecoder) parseKey() ([]string, error) {
+	var parts []string
+	for {
+		d.skipSpace()
+		var part string
+		switch c := d.peek(); {
+		case c == '"':
+			if d.hasPrefix(`"""`) {
+				return nil, d.errorf("multi-line strings are not allowed as keys")
+			}
+			s, err := d.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case c == '\'':
+			if d.hasPrefix("'''") {
+				return nil, d.errorf("multi-line strings are not allowed as keys")
+			}
+			s, err := d.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			part = s
+		case isBareKeyChar(c):
+			start := d.pos
+			for !d.eof() && isBareKeyChar(d.peek()) {
+				d.advance(1)
+			}
+			part = d.src[start:d.pos]
+		default:
+			if d.eof() || c == '\n' {
+				return nil, d.errorf("expected a key")
+			}
+			return nil, d.errorf("invalid character %q in key", c)
+		}
+		parts = append(parts, part)
+
+		d.skipSpace()
+		if d.peek() != '.' {
+			return parts, nil
+		}
+		d.advance(1)
+	}
+}
+
+func joinPath(path []string) string {
+	return strings.Join(path, ".")
+}
+
+// walk to the table at path below table, creating tables on the way. Arrays of
+// tables resolve to their last element, as toml requires.
+func (d *tomlDecoder) descend(table map[string]any, base []string, keys []string) (map[string]any, []string, error) {
+	path := append([]string{}, base...)
+	for _, key := range keys {
+		path = append(path, key)
+		switch existing := table[key].(type) {
+		case nil:
+			next := map[string]any{}
+			table[key] = next
+			d.lines[joinPath(path)] = d.line
+			table = next
+		case map[string]any:
+			if d.sealed[joinPath(path)] {
+				return nil, nil, d.errorf("cannot extend inline table %q", joinPath(path))
+			}
+			table = existing
+		case []any:
+			if len(existing) == 0 {
+				return nil, nil, d.errorf("key %q is not a table", joinPath(path))
+			}
+			last, ok := existing[len(existing)-1].(map[string]any)
+			if !ok || d.sealed[joinPath(path)] {
+				return nil, nil, d.errorf("key %q is not a table", joinPath(path))
+			}
+			path = append(path, strconv.Itoa(len(existing)-1))
+			table = last
+		default:
+			return nil, nil, d.errorf("key %q is already defined as a value", joinPath(path))
+		}
+	}
+	return table, path, nil
+}
+
+func (d *tomlDecoder) parseTableHeader() error {
+	d.advance(1)
+	keys, err := d.parseKey()
+	if err != nil {
+		return err
+	}
+	d.skipSpace()
+	if d.peek() != ']' {
+		return d.errorf("expected ] to close the table header")
+	}
+	d.advance(1)
+
+	table, path, err := d.descend(d.root, nil, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	path = append(path, last)
+	fullPath := joinPath(path)
+	switch existing := table[last].(type) {
+	case nil:
+		next := map[string]any{}
+		table[last] = next
+		table = next
+	case map[string]any:
+		// a table may be defined once, even if it was created implicitly before
+		if d.explicit[fullPath] || d.sealed[fullPath] {
+			return d.errorf("table %q is already defined", fullPath)
+		}
+		table = existing
+	default:
+		return d.errorf("key %q is already defined", fullPath)
+	}
+
+	d.explicit[fullPath] = true
+	d.lines[fullPath] = d.line
+	d.current = table
+	d.path = path
+	return nil
+}
+
+func (d *tomlDecoder) parseArrayTableHeader() error {
+	d.advance(2)
+	keys, err := d.parseKey()
+	if err != nil {
+		return err
+	}
+	d.skipSpace()
+	if !d.hasPrefix("]]") {
+		return d.errorf("expected ]] to close the array of tables header")
+	}
+	d.advance(2)
+
+	table, path, err := d.descend(d.root, nil, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	path = append(path, last)
+	fullPath := joinPath(path)
+
+	var array []any
+	switch existing := table[last].(type) {
+	case nil:
+		d.lines[fullPath] = d.line
+	case []any:
+		if d.sealed[fullPath] {
+			return d.errorf("cannot append to static array %q", fullPath)
+		}
+		array = existing
+	default:
+		return d.errorf("key %q is already defined", fullPath)
+	}
+
+	next := map[string]any{}
+	table[last] = append(array, next)
+
+	path = append(path, strconv.Itoa(len(array)))
+	d.lines[joinPath(path)] = d.line
+	d.current = next
+	d.path = path
+	return nil
+}
+
+// parse key = value into table
+func (d *tomlDecoder) parseKeyValue(table map[string]any, base []string) error {
+	d.skipSpace()
+	line, column := d.line, d.column()
+	keys, err := d.parseKey()
+	if err != nil {
+		return err
+	}
+	d.skipSpace()
+	if d.peek() != '=' {
+		return d.errorf("expected = after key %q", joinPath(keys))
+	}
+	d.advance(1)
+	d.skipSpace()
+
+	parent, path, err := d.descend(table, base, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+	// dotted keys define their intermediate tables
+	for i := range keys[:len(keys)-1] {
+		d.explicit[joinPath(append(append([]string{}, base...), keys[:i+1]...))] = true
+	}
+
+	last := keys[len(keys)-1]
+	path = append(path, last)
+	if _, exists := parent[last]; exists {
+		return d.errorAt(line, column, "duplicate key %q", joinPath(path))
+	}
+
+	value, err := d.parseValue(path)
+	if err != nil {
+		return err
+	}
+	parent[last] = value
+	d.lines[joinPath(path)] = line
+	return nil
+}
+
+func (d *tomlDecoder) parseValue(path []string) (any, error) {
+	if d.eof() {
+		return nil, d.errorf("expected a value")
+	}
+
+	switch c := d.peek(); {
+	case d.hasPrefix(`"""`):
+		return d.parseMultilineBasicString()
+	case c == '"':
+		return d.parseBasicString()
+	case d.hasPrefix("'''"):
+		return d.parseMultilineLiteralString()
+	case c == '\'':
+		return d.parseLiteralString()
+	case c == '[':
+		return d.parseArray(path)
+	case c == '{':
+		return d.parseInlineTable(path)
+	case d.hasPrefix("true") && !d.bareContinues(4):
+		d.advance(4)
+		return true, nil
+	case d.hasPrefix("false") && !d.bareContinues(5):
+		d.advance(5)
+		return false, nil
+	default:
+		return d.parseNumberOrDate()
+	}
+}
+
+// check whether a bare word continues after n bytes, eg truex
+func (d *tomlDecoder) bareContinues(n int) bool {
+	return d.pos+n < len(d.src) && isBareKeyChar(d.src[d.pos+n])
+}
+
+func (d *tomlDecoder) parseArray(path []string) ([]any, error) {
+	d.advance(1)
+	array := []any{}
+	for {
+		if err := d.skipBlank(); err != nil {
+			return nil, err
+		}
+		if d.eof() {
+			return nil, d.errorf("unterminated array")
+		}
+		if d.peek() == ']' {
+			d.advance(1)
+			d.sealed[joinPath(path)] = true
+			return array, nil
+		}
+
+		elementPath := append(append([]string{}, path...), strconv.Itoa(len(array)))
+		line := d.line
+		value, err := d.parseValue(elementPath)
+		if err != nil {
+			return nil, err
+		}
+		d.lines[joinPath(elementPath)] = line
+		array = append(array, value)
+
+		if err := d.skipBlank(); err != nil {
+			return nil, err
+		}
+		switch d.peek() {
+		case ',':
+			d.advance(1)
+		case ']':
+		default:
+			if d.eof() {
+				return nil, d.errorf("unterminated array")
+			}
+			return nil, d.errorf("expected , or ] in array, found %q", d.peek())
+		}
+	}
+}
+
+func (d *tomlDecoder) parseInlineTable(path []string) (map[string]any, error) {
+	d.advance(1)
+	table := map[string]any{}
+	d.skipSpace()
+	if d.peek() == '}' {
+		d.advance(1)
+		d.sealed[joinPath(path)] = true
+		return table, nil
+	}
+
+	for {
+		d.skipSpace()
+		if d.peek() == '\n' || d.hasPrefix("\r\n") {
+			return nil, d.errorf("newlines are not allowed in inline tables")
+		}
+		if err := d.parseKeyValue(table, path); err != nil {
+			return nil, err
+		}
+		d.skipSpace()
+		switch d.peek() {
+		case ',':
+			d.advance(1)
+			d.skipSpace()
+			if d.peek() == '}' {
+				return nil, d.errorf("trailing comma in inline table")
+			}
+		case '}':
+			d.advance(1)
+			d.sealed[joinPath(path)] = true
+			return table, nil
+		default:
+			if d.eof() {
+				return nil, d.errorf("unterminated inline table")
+			}
+			return nil, d.errorf("expected , or } in inline table, found %q", d.peek())
+		}
+	}
+}
+
+func (d *tomlDecoder) parseEscape(builder *strings.Builder) error {
+	d.advance(1)
+	if d.eof() {
+		return d.errorf("unterminated escape sequence")
+	}
+	c := d.peek()
+	switch c {
+	case 'b':
+		builder.WriteByte('\b')
+	case 't':
+		builder.WriteByte('\t')
+	case 'n':
+		builder.WriteByte('\n')
+	case 'f':
+		builder.WriteByte('\f')
+	case 'r':
+		builder.WriteByte('\r')
+	case 'e':
+		builder.WriteByte(0x1b)
+	case '"':
+		builder.WriteByte('"')
+	case '\\':
+		builder.WriteByte('\\')
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if d.pos+1+size > len(d.src) {
+			return d.errorf("short unicode escape")
+		}
+		code, err := strconv.ParseUint(d.src[d.pos+1:d.pos+1+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return d.errorf("invalid unicode escape \\%c%s", c, d.src[d.pos+1:d.pos+1+size])
+		}
+		builder.WriteRune(rune(code))
+		d.advance(size)
+	default:
+		return d.errorf("invalid escape sequence \\%c", c)
+	}
+	d.advance(1)
+	return nil
+}
+
+func (d *tomlDecoder) parseBasicString() (string, error) {
+	d.advance(1)
+	var builder strings.Builder
+	for {
+		if d.eof() || d.peek() == '\n' {
+			return "", d.errorf("unterminated string")
+		}
+		switch c := d.peek(); c {
+		case '"':
+			d.advance(1)
+			return builder.String(), nil
+		case '\\':
+			if err := d.parseEscape(&builder); err != nil {
+				return "", err
+			}
+		default:
+			if c < 0x20 && c != '\t' || c == 0x7f {
+				return "", d.errorf("control character in string")
+			}
+			builder.WriteByte(c)
+			d.advance(1)
+		}
+	}
+}
+
+func (d *tomlDecoder) parseMultilineBasicString() (string, error) {
+	d.advance(3)
+	// a newline right after the opening quotes is trimmed
+	if d.hasPrefix("\r\n") {
+		d.advance(2)
+	} else if d.peek() == '\n' {
+		d.advance(1)
+	}
+
+	var builder strings.Builder
+	for {
+		if d.eof() {
+			return "", d.errorf("unterminated multi-line string")
+		}
+		if d.hasPrefix(`"""`) {
+			// up to two extra quotes may close the string
+			d.advance(3)
+			for i := 0; i < 2 && d.peek() == '"'; i++ {
+				builder.WriteByte('"')
+				d.advance(1)
+			}
+			return builder.String(), nil
+		}
+
+		c := d.peek()
+		if c == '\\' {
+			// a line ending backslash trims all whitespace up to the next text
+			rest := strings.TrimLeft(d.src[d.pos+1:], " \t")
+			if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+				d.advance(1)
+				for !d.eof() && strings.ContainsRune(" \t\r\n", rune(d.peek())) {
+					d.advance(1)
+				}
+				continue
+			}
+			if err := d.parseEscape(&builder); err != nil {
+				return "", err
+			}
+			continue
+		}
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' || c == 0x7f {
+			return "", d.errorf("control character in string")
+		}
+		builder.WriteByte(c)
+		d.advance(1)
+	}
+}
+
+func (d *tomlDecoder) parseLiteralString() (string, error) {
+	d.advance(1)
+	start := d.pos
+	for {
+		if d.eof() || d.peek() == '\n' {
+			return "", d.errorf("unterminated string")
+		}
+		if d.peek() == '\'' {
+			value := d.src[start:d.pos]
+			d.advance(1)
+			return value, nil
+		}
+		d.advance(1)
+	}
+}
+
+func (d *tomlDecoder) parseMultilineLiteralString() (string, error) {
+	d.advance(3)
+	if d.hasPrefix("\r\n") {
+		d.advance(2)
+	} else if d.peek() == '\n' {
+		d.advance(1)
+	}
+
+	start := d.pos
+	for {
+		if d.eof() {
+			return "", d.errorf("unterminated multi-line string")
+		}
+		if d.hasPrefix("'''") {
+			end := d.pos
+			d.advance(3)
+			for i := 0; i < 2 && d.peek() == '\''; i++ {
+				end++
+				d.advance(1)
+			}
+			return d.src[start:end], nil
+		}
+		d.advance(1)
+	}
+}
+
+func isValueEnd(c byte) bool {
+	return c == 0 || c == ',' || c == ']' || c == '}' || c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '#'
+}
+
+func (d *tomlDecoder) parseNumberOrDate() (any, error) {
+	start := d.pos
+	for !d.eof() && !isValueEnd(d.peek()) {
+		d.advance(1)
+	}
+	// dates may contain a single space between the date and time
+	if d.peek() == ' ' && d.pos-start == 10 && strings.Count(d.src[start:d.pos], "-") == 2 &&
+		d.pos+1 < len(d.src) && d.src[d.pos+1] >= '0' && d.src[d.pos+1] <= '9' {
+		d.advance(1)
+		for !d.eof() && !isValueEnd(d.peek()) {
+			d.advance(1)
+		}
+	}
+
+	raw := d.src[start:d.pos]
+	if raw == "" {
+		return nil, d.errorf("expected a value")
+	}
+
+	switch raw {
+	case "inf", "+inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	case "nan", "+nan", "-nan":
+		return math.NaN(), nil
+	}
+
+	// dates and times are kept as their text
+	if isTOMLDate(raw) {
+		return raw, nil
+	}
+
+	if strings.Contains(raw, "__") || strings.HasPrefix(raw, "_") || strings.HasSuffix(raw, "_") {
+		return nil, d.errorf("invalid number %q", raw)
+	}
+	clean := strings.ReplaceAll(raw, "_", "")
+
+	if len(clean) > 2 && clean[0] == '0' && strings.ContainsRune("xob", rune(clean[1])) {
+		base := map[byte]int{'x': 16, 'o': 8, 'b': 2}[clean[1]]
+		value, err := strconv.ParseInt(clean[2:], base, 64)
+		if err != nil {
+			return nil, d.errorf("invalid number %q", raw)
+		}
+		return value, nil
+	}
+
+	digits := strings.TrimLeft(clean, "+-")
+	if len(digits) > 1 && digits[0] == '0' && digits[1] != '.' && digits[1] != 'e' && digits[1] != 'E' {
+		return nil, d.errorf("leading zeros are not allowed in %q", raw)
+	}
+
+	if strings.ContainsAny(clean, ".eE") {
+		value, err := strconv.ParseFloat(clean, 64)
+		if err != nil || strings.HasSuffix(clean, ".") || strings.Contains(clean, ".e") {
+			return nil, d.errorf("invalid float %q", raw)
+		}
+		return value, nil
+	}
+
+	value, err := strconv.ParseInt(clean, 10, 64)
+	if err != nil {
+		return nil, d.errorf("invalid value %q", raw)
+	}
+	return value, nil
+}
+
+// loose check for toml date, time and datetime values
+func isTOMLDate(raw string) bool {
+	if len(raw) >= 10 && raw[4] == '-' && raw[7] == '-' {
+		return true
+	}
+	return len(raw) >= 8 && raw[2] == ':' && raw[5] == ':'
+}
+
+// lookupTOML walks a decoded tree by dotted path eg font.normal.family
+func lookupTOML(tree map[string]any, path string) (any, bool) {
+	var current any = tree
+	for _, key := range strings.Split(path, ".") {
+		table, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = table[key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// formatTOMLValue renders a decoded value back as toml text
+func formatTOMLValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, element := range v {
+			if element == nil {
+				// toml has no null, yaml nulls are left out
+				continue
+			}
+			if s, ok := element.(string); ok {
+				parts = append(parts, quoteTOML(s))
+			} else {
+				parts = append(parts, formatTOMLValue(element))
+			}
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key, element := range v {
+			if element != nil {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			element := v[key]
+			if !isBareKey(key) {
+				key = quoteTOML(key)
+			}
+			if s, ok := element.(string); ok {
+				parts = append(parts, key+" = "+quoteTOML(s))
+			} else {
+				parts = append(parts, key+" = "+formatTOMLValue(element))
+			}
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	case nil:
+		return ""
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// quoteTOML writes s as a basic TOML string, control characters use the
+// \uXXXX escapes TOML understands
+func quoteTOML(s string) string {
+	var builder strings.Builder
+	builder.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			builder.WriteString(`\"`)
+		case '\\':
+			builder.WriteString(`\\`)
+		case '\n':
+			builder.WriteString(`\n`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\r':
+			builder.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				builder.WriteString(fmt.Sprintf(`\u%04X`, r))
+				continue
+			}
+			builder.WriteRune(r)
+		}
+	}
+	builder.WriteByte('"')
+	return builder.String()
+}
+
+// bare keys need no quotes
+func isBareKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, c := range key {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
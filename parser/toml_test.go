@@ -0,0 +1,117 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeTOML(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+		want map[string]any
+	}{
+		{
+			"inline table",
+			`font = { size = 12, normal = { family = "Hack" } }`,
+			map[string]any{"font": map[string]any{"size": int64(12), "normal": map[string]any{"family": "Hack"}}},
+		},
+		{
+			"array of tables",
+			"[[keyboard.bindings]]\nkey = \"N\"\naction = \"SpawnNewInstance\"\n\n[[keyboard.bindings]]\nkey = \"Q\"\nmods = \"Control\"\n",
+			map[string]any{"keyboard": map[string]any{"bindings": []any{
+				map[string]any{"key": "N", "action": "SpawnNewInstance"},
+				map[string]any{"key": "Q", "mods": "Control"},
+			}}},
+		},
+		{
+			"basic string escapes",
+			`chars = "\t\"\\\u00e9\U0001F600"`,
+			map[string]any{"chars": "\t\"\\\u00e9\U0001F600"},
+		},
+		{
+			"literal string",
+			`path = 'C:\Users\me'`,
+			map[string]any{"path": `C:\Users\me`},
+		},
+		{
+			"multiline string",
+			"text = \"\"\"\nfirst\\\n  second\"\"\"",
+			map[string]any{"text": "firstsecond"},
+		},
+		{
+			"dotted keys",
+			"colors.primary.background = \"#000000\"\n\"window\".padding.x = 4\n",
+			map[string]any{
+				"colors": map[string]any{"primary": map[string]any{"background": "#000000"}},
+				"window": map[string]any{"padding": map[string]any{"x": int64(4)}},
+			},
+		},
+		{
+			"dotted keys in a table",
+			"[window]\npadding.x = 4\npadding.y = 2\n",
+			map[string]any{"window": map[string]any{"padding": map[string]any{"x": int64(4), "y": int64(2)}}},
+		},
+		{
+			"numbers",
+			"a = 0x1f\nb = 1_000\nc = -0.5\nd = 1e2\n",
+			map[string]any{"a": int64(31), "b": int64(1000), "c": -0.5, "d": 100.0},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeTOML("test.toml", []byte(test.toml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tree.Root, test.want) {
+				t.Errorf("decodeTOML() = %#v, want %#v", tree.Root, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		toml string
+	}{
+		{"duplicate key", "a = 1\na = 2\n"},
+		{"unterminated string", `a = "open`},
+		{"unknown escape", `a = "\q"`},
+		{"redefined table", "[a]\nb = 1\n[a]\nc = 2\n"},
+		{"missing value", "a =\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := decodeTOML("test.toml", []byte(test.toml)); err == nil {
+				t.Errorf("decodeTOML(%q) succeeded, want an error", test.toml)
+			}
+		})
+	}
+}
+
+func TestFormatTOMLValue(t *testing.T) {
+	tests := []struct {
+		name  string
+		value any
+		want  string
+	}{
+		{"string", "JetBrains Mono", "JetBrains Mono"},
+		{"bool", true, "true"},
+		{"int", int64(12), "12"},
+		{"float", 0.5, "0.5"},
+		{"array", []any{"-l", "-c"}, `["-l", "-c"]`},
+		{"table", map[string]any{"key": "A", "mods": "Control"}, `{ key = "A", mods = "Control" }`},
+		{"null", nil, ""},
+		{"null in array", []any{"-l", nil}, `["-l"]`},
+		{"null in table", map[string]any{"key": "A", "chars": nil}, `{ key = "A" }`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := formatTOMLValue(test.value); got != test.want {
+				t.Errorf("formatTOMLValue(%#v) = %s, want %s", test.value, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,269 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// setting is a single ghostty key and value produced by a conversion
+type setting struct {
+	key   string
+	value string
+
+	// why the value only approximates the source setting, if it does
+	approx string
+}
+
+// valueTransform converts a source value into zero or more ghostty settings.
+// No settings and no error means the value is the default and can be left
+// out. Values ghostty cannot express return an *unrepresentableError.
+type valueTransform func(value string) ([]setting, error)
+
+// unrepresentableError marks a value that has no ghostty equivalent
+type unrepresentableError struct {
+	value  string
+	reason string
+}
+
+func (e *unrepresentableError) Error() string {
+	if e.value == "" {
+		return e.reason
+	}
+	return fmt.Sprintf("%q cannot be represented in ghostty: %s", e.value, e.reason)
+}
+
+func unrepresentable(value, format string, args ...any) error {
+	return &unrepresentableError{value: value, reason: fmt.Sprintf(format, args...)}
+}
+
+// rename copies the value verbatim to the ghostty key
+func rename(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		return []setting{{key: key, value: value}}, nil
+	}
+}
+
+// enum remaps values case insensitively, unknown values can't be represented.
+// Mapping a value to "" drops it as the ghostty default.
+func enum(key string, values map[string]string) valueTransform {
+	return func(value string) ([]setting, error) {
+		mapped, ok := values[strings.ToLower(value)]
+		if !ok {
+			return nil, unrepresentable(value, "no matching %s value", key)
+		}
+		if mapped == "" {
+			return nil, nil
+		}
+		return []setting{{key: key, value: mapped}}, nil
+	}
+}
+
+// enumSettings remaps values case insensitively to a set of ghostty settings,
+// for source values that are split over several ghostty keys
+func enumSettings(values map[string][]setting) valueTransform {
+	return func(value string) ([]setting, error) {
+		mapped, ok := values[strings.ToLower(value)]
+		if !ok {
+			return nil, unrepresentable(value, "unknown value")
+		}
+		return mapped, nil
+	}
+}
+
+// boolean coerces yes/no style values to ghostty's true/false
+func boolean(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		parsed, ok := parseBool(value)
+		if !ok {
+			return nil, unrepresentable(value, "expected a boolean for %s", key)
+		}
+		return []setting{{key: key, value: strconv.FormatBool(parsed)}}, nil
+	}
+}
+
+func parseBool(value string) (bool, bool) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "y", "true", "on", "always", "1":
+		return true, true
+	case "no", "n", "false", "off", "never", "0":
+		return false, true
+	}
+	return false, false
+}
+
+// number checks the value is numeric and writes it in plain notation
+func number(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return nil, unrepresentable(value, "expected a number for %s", key)
+		}
+		return []setting{{key: key, value: formatNumber(parsed)}}, nil
+	}
+}
+
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// color normalises a color value for the ghostty key
+func color(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		normalised, err := normaliseColor(value)
+		if err != nil {
+			return nil, err
+		}
+		return []setting{{key: key, value: normalised}}, nil
+	}
+}
+
+// the number of colors in ghostty's palette
+const paletteSize = 256
+
+// paletteColor writes a color as an indexed palette entry eg palette = 4=#268bd2
+func paletteColor(index int) valueTransform {
+	return func(value string) ([]setting, error) {
+		if index < 0 || index >= paletteSize {
+			return nil, unrepresentable(value, "palette index %d is out of range, ghostty has colors 0 to %d", index, paletteSize-1)
+		}
+		normalised, err := normaliseColor(value)
+		if err != nil {
+			return nil, err
+		}
+		return []setting{{key: "palette", value: fmt.Sprintf("%d=%s", index, normalised)}}, nil
+	}
+}
+
+// pixels writes a whole number of pixels for one of ghostty's adjust-* metrics
+func pixels(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || math.IsNaN(parsed) || math.IsInf(parsed, 0) {
+			return nil, unrepresentable(value, "expected a number of pixels for %s", key)
+		}
+		return []setting{pixelSetting(key, parsed, "")}, nil
+	}
+}
+
+// pixelSetting rounds a metric to whole pixels, noting the rounding in approx
+func pixelSetting(key string, value float64, approx string) setting {
+	rounded := math.Round(value)
+	if rounded != value {
+		approx = strings.TrimPrefix(approx+"; ghostty metrics are whole pixels, rounded from "+formatNumber(math.Round(value*100)/100), "; ")
+	}
+	return setting{key, formatNumber(rounded), approx}
+}
+
+// ghostty stores scrollback in bytes, this is roughly what one line of a
+// wide terminal costs
+const scrollbackBytesPerLine = 2000
+
+// scrollbackLines converts a line count into ghostty's byte limit
+func scrollbackLines(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		lines, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return nil, unrepresentable(value, "expected a number of lines")
+		}
+		if lines < 0 {
+			// unlimited, ghostty needs a limit so use an arbitrary large one
+			return []setting{{key, strconv.FormatUint(math.MaxUint32, 10), "ghostty has no unlimited scrollback, capped at an arbitrary 4 GiB"}}, nil
+		}
+		approx := fmt.Sprintf("ghostty limits scrollback in bytes, estimated at %d bytes per line", scrollbackBytesPerLine)
+		return []setting{{key, strconv.FormatInt(lines*scrollbackBytesPerLine, 10), approx}}, nil
+	}
+}
+
+// cells converts a size given in cells (eg 80c) to ghostty's cell count,
+// pixel sizes have no ghostty equivalent
+func cells(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		value = strings.TrimSpace(value)
+		if !strings.HasSuffix(value, "c") {
+			return nil, unrepresentable(value, "ghostty sizes windows in cells, not pixels")
+		}
+		count, err := strconv.ParseUint(strings.TrimSuffix(value, "c"), 10, 32)
+		if err != nil {
+			return nil, unrepresentable(value, "expected a number of cells")
+		}
+		return []setting{{key: key, value: strconv.FormatUint(count, 10)}}, nil
+	}
+}
+
+// nonZero turns an interval into a boolean, zero disables the feature and a
+// negative value keeps the system default
+func nonZero(key string) valueTransform {
+	return func(value string) ([]setting, error) {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, unrepresentable(value, "expected a number")
+		}
+		if parsed < 0 {
+			return nil, nil
+		}
+		if parsed > 0 {
+			return []setting{{key, "true", "ghostty only turns this on or off, the interval is dropped"}}, nil
+		}
+		return []setting{{key, "false", ""}}, nil
+	}
+}
+
+// unlessDefault drops the value when it is the source terminal's default
+func unlessDefault(defaultValue string, transform valueTransform) valueTransform {
+	return func(value string) ([]setting, error) {
+		if strings.EqualFold(strings.TrimSpace(value), defaultValue) {
+			return nil, nil
+		}
+		return transform(value)
+	}
+}
+
+// approximately notes that the settings produced by transform only come
+// close to the source behaviour
+func approximately(reason string, transform valueTransform) valueTransform {
+	return func(value string) ([]setting, error) {
+		settings, err := transform(value)
+		for i := range settings {
+			settings[i].approx = reason
+		}
+		return settings, err
+	}
+}
+
+// unsupported marks a setting ghostty has no equivalent for
+func unsupported(reason string) valueTransform {
+	return func(value string) ([]setting, error) {
+		return nil, &unrepresentableError{reason: reason}
+	}
+}
+
+// an entry that could not be converted and why
+type unmappedSetting struct {
+	entry  *Entry
+	reason string
+}
+
+// convertEntry applies the transform for entry from table and adds the result
+// to ghosttyConfig. handled is false when the table has no mapping for the key.
+func convertEntry(table map[string]valueTransform, ghosttyConfig *Document, entry *Entry) ([]setting, bool, error) {
+	transform, exists := table[entry.Key]
+	if !exists {
+		return nil, false, nil
+	}
+
+	var converted []setting
+	for _, value := range entry.Values {
+		settings, err := transform(value)
+		if err != nil {
+			return nil, true, err
+		}
+		converted = append(converted, settings...)
+	}
+
+	for _, s := range converted {
+		ghosttyConfig.Add(s.key, s.value, entry.Source, entry.Line)
+	}
+	return converted, true, nil
+}
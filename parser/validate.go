@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"fmt"
+	"ghostty-ghost/ghostty"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Severity of a validation problem
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Problem is something wrong with one line of a ghostty config
+type Problem struct {
+	Source   string
+	Line     int
+	Key      string
+	Severity Severity
+	Message  string
+}
+
+func (p Problem) String() string {
+	origin := p.Source
+	if p.Line != 0 {
+		origin = fmt.Sprintf("%s:%d", p.Source, p.Line)
+	}
+	return fmt.Sprintf("%s: %s", origin, p.Message)
+}
+
+// ValidateGhostty checks every enabled entry of a ghostty config against the
+// ghostty schema
+func ValidateGhostty(config *Document) []Problem {
+	var problems []Problem
+	report := func(entry *Entry, severity Severity, format string, args ...any) {
+		problems = append(problems, Problem{
+			Source:   entry.Source,
+			Line:     entry.Line,
+			Key:      entry.Key,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := make(map[string]*Entry)
+	for _, entry := range config.Entries {
+		if entry.Key == "" || entry.Disabled {
+			continue
+		}
+
+		option, ok := ghostty.Lookup(entry.Key)
+		if !ok {
+			if suggestion := ghostty.Suggest(entry.Key); suggestion != "" {
+				report(entry, SeverityError, "unknown key %q, did you mean %q?", entry.Key, suggestion)
+			} else {
+				report(entry, SeverityError, "unknown key %q", entry.Key)
+			}
+			continue
+		}
+
+		for _, value := range entry.Values {
+			if err := option.Check(unquoteGhostty(value)); err != nil {
+				report(entry, SeverityError, "%s: %v", entry.Key, err)
+			}
+		}
+
+		if !option.AppliesHere() {
+			platform := map[string]string{"macos": "macOS", "linux": "Linux"}[option.Platform]
+			report(entry, SeverityWarning, "%s only has an effect on %s", entry.Key, platform)
+		}
+
+		if previous, ok := seen[entry.Key]; ok && !option.Repeatable {
+			report(entry, SeverityWarning, "%s is already set at %s:%d, the last value wins", entry.Key, previous.Source, previous.Line)
+		}
+		seen[entry.Key] = entry
+	}
+	return problems
+}
+
+// ghostty allows values to be wrapped in double quotes
+func unquoteGhostty(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// HasErrors reports whether any problem is an error rather than a warning
+func HasErrors(problems []Problem) bool {
+	for _, problem := range problems {
+		if problem.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateGhosttyFile validates the ghostty config at path and the files it
+// loads with config-file
+func ValidateGhosttyFile(path string) ([]Problem, error) {
+	files, err := loadGhosttyFiles(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, file := range files {
+		problems = append(problems, ValidateGhostty(file.config)...)
+		problems = append(problems, file.problems...)
+	}
+	return problems, nil
+}
+
+// a ghostty config file and its config-file lines that can't be loaded
+type ghosttyFile struct {
+	path     string
+	config   *Document
+	problems []Problem
+}
+
+// loadGhosttyFiles reads the ghostty config at path and the files it loads
+// with config-file, in the order ghostty applies them: each file before the
+// files it names. Missing files and files that include themselves are
+// problems of the file that names them.
+func loadGhosttyFiles(path string) ([]ghosttyFile, error) {
+	var files []ghosttyFile
+	var load func(path string, stack []string) error
+	load = func(path string, stack []string) error {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		stack = append(stack, absolutePath(path))
+
+		file := ghosttyFile{path: path, config: ParseGhosttyConfig(path, string(contents))}
+		var includes []string
+		for _, entry := range file.config.Lookup("config-file") {
+			value := unquoteGhostty(entry.Value())
+			// a leading ? makes the file optional
+			optional := strings.HasPrefix(value, "?")
+			value = strings.TrimPrefix(value, "?")
+			if value == "" {
+				continue
+			}
+
+			// config-file paths are relative to the file that loads them
+			included := resolveImportPath(value, filepath.Dir(path))
+			if slices.Contains(stack, absolutePath(included)) {
+				file.problems = append(file.problems, Problem{path, entry.Line, entry.Key, SeverityError, fmt.Sprintf("config-file %s includes itself", value)})
+				continue
+			}
+			if _, err := os.Stat(included); err != nil {
+				if !optional {
+					file.problems = append(file.problems, Problem{path, entry.Line, entry.Key, SeverityError, fmt.Sprintf("config-file %s does not exist", value)})
+				}
+				continue
+			}
+			includes = append(includes, included)
+		}
+
+		files = append(files, file)
+		for _, included := range includes {
+			if err := load(included, stack); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := load(path, nil); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,45 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateGhostty(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		severity Severity
+		message  string
+	}{
+		{"valid", "font-size = 12\ntheme = Dracula\nkeybind = ctrl+a=select_all\n", "", ""},
+		{"unknown key", "not-a-key = 1\n", SeverityError, `unknown key "not-a-key"`},
+		{"misspelt key", "font-sise = 12\n", SeverityError, `did you mean "font-size"?`},
+		{"bad number", "font-size = big\n", SeverityError, "font-size"},
+		{"bad color", "background = #12345g\n", SeverityError, "background"},
+		{"bad palette index", "palette = 300=#000000\n", SeverityError, "palette"},
+		{"bad enum", "cursor-style = triangle\n", SeverityError, "cursor-style"},
+		{"unknown action", "keybind = ctrl+a=not_an_action\n", SeverityError, "keybind"},
+		{"missing action", "keybind = ctrl+a\n", SeverityError, "keybind"},
+		{"set twice", "font-size = 12\nfont-size = 13\n", SeverityWarning, "already set at config:1"},
+		{"repeatable", "keybind = ctrl+a=select_all\nkeybind = ctrl+b=copy_to_clipboard\n", "", ""},
+		{"disabled", "# not-a-key = 1\n", "", ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := ValidateGhostty(ParseGhosttyConfig("config", test.config))
+			if test.message == "" {
+				if len(problems) > 0 {
+					t.Errorf("ValidateGhostty() = %v, want no problems", problems)
+				}
+				return
+			}
+			if len(problems) != 1 {
+				t.Fatalf("ValidateGhostty() = %v, want one problem", problems)
+			}
+			if problems[0].Severity != test.severity || !strings.Contains(problems[0].Message, test.message) {
+				t.Errorf("ValidateGhostty() = %s %q, want %s containing %q", problems[0].Severity, problems[0].Message, test.severity, test.message)
+			}
+		})
+	}
+}
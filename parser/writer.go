@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+)
+
+// RenderGhostty formats a document as a ghostty config, one line per value in
+// document order
+func RenderGhostty(config *Document) string {
+	var builder strings.Builder
+	for _, entry := range config.Entries {
+		for _, comment := range entry.Comments {
+			if comment == "" {
+				builder.WriteString("\n")
+				continue
+			}
+			builder.WriteString("# " + comment + "\n")
+		}
+		if entry.Key == "" {
+			continue
+		}
+
+		prefix := ""
+		if entry.Disabled {
+			prefix = "# "
+		}
+		for _, value := range entry.Values {
+			builder.WriteString(fmt.Sprintf("%s%s = %s\n", prefix, entry.Key, value))
+		}
+	}
+	return builder.String()
+}
+
+// backup the existing file and write the new contents in its place
+func writeWithBackup(filepath string, contents string) error {
+	// Create directory if it doesn't exist
+	dir := path.Dir(filepath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	// before writing the file backup the old one if there is one
+	if _, err := os.Stat(filepath); err == nil {
+		backupPath := filepath + ".bak"
+		// Remove existing backup if it exists
+		if _, err := os.Stat(backupPath); err == nil {
+			if err := os.Remove(backupPath); err != nil {
+				return fmt.Errorf("failed to remove existing backup: %w", err)
+			}
+		}
+
+		// Create new backup
+		if err := os.Rename(filepath, backupPath); err != nil {
+			return fmt.Errorf("failed to create backup: %w", err)
+		}
+	}
+
+	return os.WriteFile(filepath, []byte(contents), 0644)
+}
+
+// write a ghostty config document to disk
+func writeGhosttyConfig(filepath string, config *Document) error {
+	return writeWithBackup(filepath, RenderGhostty(config))
+}
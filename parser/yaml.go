@@ -0,0 +1,767 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// YAMLError is a decode error with the line it was found on
+type YAMLError struct {
+	File    string
+	Line    int
+	Message string
+}
+
+func (e *YAMLError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("line %d: %s", e.Line, e.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s", e.File, e.Line, e.Message)
+}
+
+// a source line with its indentation and without a trailing comment
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+	raw    string
+	blank  bool
+}
+
+// yamlDecoder reads the block and flow yaml used by legacy alacritty.yml
+// files into the same tree shape as the toml decoder. Anchors, aliases and
+// merge keys are supported, tags are ignored.
+type yamlDecoder struct {
+	file    string
+	lines   []yamlLine
+	pos     int
+	paths   map[string]int
+	anchors map[string]any
+}
+
+// decode a yaml file into a tree, errors carry the line
+func decodeYAML(file string, data []byte) (*decodedTree, error) {
+	d := &yamlDecoder{
+		file:    file,
+		paths:   map[string]int{},
+		anchors: map[string]any{},
+	}
+
+	src := strings.TrimPrefix(string(data), "\uFEFF")
+	for i, raw := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		trimmed := strings.TrimLeft(raw, " ")
+		line := yamlLine{num: i + 1, indent: len(raw) - len(trimmed), raw: raw}
+		if strings.HasPrefix(trimmed, "\t") {
+			return nil, d.errorf(line.num, "tabs are not allowed for indentation")
+		}
+		line.text = strings.TrimRight(stripYAMLComment(trimmed), " \t")
+		line.blank = line.text == "" || line.text == "---" || line.text == "..." || strings.HasPrefix(line.text, "%")
+		d.lines = append(d.lines, line)
+	}
+
+	root := map[string]any{}
+	if line := d.current(); line != nil {
+		value, err := d.parseNode(line.indent, nil)
+		if err != nil {
+			return nil, err
+		}
+		if line := d.current(); line != nil {
+			return nil, d.errorf(line.num, "unexpected content %q", line.text)
+		}
+		table, ok := value.(map[string]any)
+		if !ok && value != nil {
+			return nil, d.errorf(line.num, "expected a mapping at the top level")
+		}
+		if table != nil {
+			root = table
+		}
+	}
+
+	return &decodedTree{Root: root, Lines: d.paths}, nil
+}
+
+func (d *yamlDecoder) errorf(line int, format string, args ...any) error {
+	return &YAMLError{File: d.file, Line: line, Message: fmt.Sprintf(format, args...)}
+}
+
+// the next line with content, nil at the end of the file
+func (d *yamlDecoder) current() *yamlLine {
+	for d.pos < len(d.lines) && d.lines[d.pos].blank {
+		d.pos++
+	}
+	if d.pos >= len(d.lines) {
+		return nil
+	}
+	return &d.lines[d.pos]
+}
+
+// strip a comment that is not inside quotes, # only starts a comment at the
+// start of the line or after whitespace
+func stripYAMLComment(text string) string {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || strings.ContainsRune(" \t[{,:-", rune(text[i-1])) {
+				quote = c
+			}
+		case c == '#':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '\t' {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// split "key: value" on the first colon outside quotes and brackets
+func splitMappingLine(text string) (string, string, bool) {
+	if text == "" || text[0] == '[' || text[0] == '{' || text[0] == '*' || text[0] == '&' || text[0] == '!' {
+		return "", "", false
+	}
+
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && i == 0:
+			quote = c
+		case c == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t'):
+			key := strings.TrimSpace(text[:i])
+			if unquoted, err := unquoteYAML(key); err == nil {
+				key = unquoted
+			}
+			return key, strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// parse the block node starting at the current line
+func (d *yamlDecoder) parseNode(indent int, path []string) (any, error) {
+	line := d.current()
+	if line == nil || line.indent < indent {
+		return nil, nil
+	}
+	if isSequenceItem(line.text) {
+		return d.parseSequence(line.indent, path)
+	}
+	if _, _, ok := splitMappingLine(line.text); ok {
+		return d.parseMapping(line.indent, path)
+	}
+
+	// a value on its own line
+	d.pos++
+	return d.parseInlineValue(line.text, line, line.indent-1, path)
+}
+
+func (d *yamlDecoder) parseMapping(indent int, path []string) (map[string]any, error) {
+	table := map[string]any{}
+	for {
+		line := d.current()
+		if line == nil || line.indent < indent {
+			return table, nil
+		}
+		if line.indent > indent {
+			return nil, d.errorf(line.num, "unexpected indentation")
+		}
+		if isSequenceItem(line.text) {
+			return table, nil
+		}
+
+		key, rest, ok := splitMappingLine(line.text)
+		if !ok {
+			return nil, d.errorf(line.num, "expected key: value, found %q", line.text)
+		}
+		d.pos++
+
+		keyPath := append(append([]string{}, path...), key)
+		value, err := d.parseValue(rest, line, indent, keyPath, true)
+		if err != nil {
+			return nil, err
+		}
+
+		// merge keys copy the settings of an anchor without overriding
+		if key == "<<" {
+			sources, ok := value.([]any)
+			if !ok {
+				sources = []any{value}
+			}
+			for _, source := range sources {
+				sourceTable, ok := source.(map[string]any)
+				if !ok {
+					return nil, d.errorf(line.num, "merge key expects a mapping")
+				}
+				for sourceKey, sourceValue := range sourceTable {
+					if _, exists := table[sourceKey]; !exists {
+						table[sourceKey] = sourceValue
+						d.setLines(sourceValue, append(append([]string{}, path...), sourceKey), line.num)
+					}
+				}
+			}
+			continue
+		}
+
+		table[key] = value
+		d.paths[joinPath(keyPath)] = line.num
+	}
+}
+
+func (d *yamlDecoder) parseSequence(indent int, path []string) ([]any, error) {
+	array := []any{}
+	for {
+		line := d.current()
+		if line == nil || line.indent != indent || !isSequenceItem(line.text) {
+			if line != nil && line.indent > indent {
+				return nil, d.errorf(line.num, "unexpected indentation")
+			}
+			return array, nil
+		}
+
+		itemPath := append(append([]string{}, path...), strconv.Itoa(len(array)))
+		rest := strings.TrimSpace(strings.TrimPrefix(line.text, "-"))
+		d.paths[joinPath(itemPath)] = line.num
+
+		var value any
+		var err error
+		_, _, isMapping := splitMappingLine(rest)
+		switch {
+		case rest == "":
+			d.pos++
+			if next := d.current(); next != nil && next.indent > indent {
+				value, err = d.parseNode(next.indent, itemPath)
+			}
+		case isMapping || isSequenceItem(rest):
+			// the item starts on the same line, continue it as a nested block
+			// indented to where its content starts
+			line.indent += len(line.text) - len(rest)
+			line.text = rest
+			value, err = d.parseNode(line.indent, itemPath)
+		default:
+			d.pos++
+			value, err = d.parseValue(rest, line, indent, itemPath, false)
+		}
+		if err != nil {
+			return nil, err
+		}
+		array = append(array, value)
+	}
+}
+
+// parse the value after a key or sequence marker, continuing onto the
+// following lines for nested blocks, block scalars and flow collections
+func (d *yamlDecoder) parseValue(rest string, line *yamlLine, indent int, path []string, inMapping bool) (any, error) {
+	rest, anchor := takeYAMLAnchor(rest)
+	rest = skipYAMLTag(rest)
+
+	var value any
+	var err error
+	switch {
+	case rest == "":
+		next := d.current()
+		if next != nil && next.indent > indent {
+			value, err = d.parseNode(next.indent, path)
+		} else if next != nil && inMapping && next.indent == indent && isSequenceItem(next.text) {
+			// sequences may sit at the same indentation as their key
+			value, err = d.parseSequence(indent, path)
+		}
+	case rest[0] == '|' || rest[0] == '>':
+		value = d.parseBlockScalar(rest, indent)
+	default:
+		value, err = d.parseInlineValue(rest, line, indent, path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor != "" {
+		d.anchors[anchor] = value
+	}
+	return value, nil
+}
+
+// parse a value that starts on the current line
+func (d *yamlDecoder) parseInlineValue(rest string, line *yamlLine, indent int, path []string) (any, error) {
+	// flow collections and quoted strings may continue on the next lines
+	if rest[0] == '[' || rest[0] == '{' || rest[0] == '"' || rest[0] == '\'' {
+		for !yamlBalanced(rest) {
+			next := d.current()
+			if next == nil || next.indent <= indent && !strings.HasPrefix(next.text, "]") && !strings.HasPrefix(next.text, "}") {
+				return nil, d.errorf(line.num, "unterminated value %q", rest)
+			}
+			rest += " " + next.text
+			d.pos++
+		}
+	}
+
+	flow := &yamlFlow{decoder: d, src: rest, line: line.num}
+	value, err := flow.parseValue(path, false)
+	if err != nil {
+		return nil, err
+	}
+	flow.skipSpace()
+	if flow.pos < len(flow.src) {
+		return nil, d.errorf(line.num, "unexpected %q after value", flow.src[flow.pos:])
+	}
+	return value, nil
+}
+
+// collect a literal (|) or folded (>) block scalar
+func (d *yamlDecoder) parseBlockScalar(header string, indent int) string {
+	var lines []string
+	contentIndent := -1
+	for d.pos < len(d.lines) {
+		line := d.lines[d.pos]
+		if strings.TrimSpace(line.raw) == "" {
+			lines = append(lines, "")
+			d.pos++
+			continue
+		}
+		if line.indent <= indent {
+			break
+		}
+		if contentIndent < 0 {
+			contentIndent = line.indent
+		}
+		lines = append(lines, line.raw[min(contentIndent, line.indent):])
+		d.pos++
+	}
+
+	// trailing blank lines belong to whatever follows
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	separator := "\n"
+	if header[0] == '>' {
+		separator = " "
+	}
+	value := strings.Join(lines, separator)
+	if !strings.Contains(header, "-") {
+		value += "\n"
+	}
+	return value
+}
+
+// record line for every path below value
+func (d *yamlDecoder) setLines(value any, path []string, line int) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, element := range v {
+			d.setLines(element, append(append([]string{}, path...), key), line)
+		}
+	case []any:
+		for i, element := range v {
+			d.setLines(element, append(append([]string{}, path...), strconv.Itoa(i)), line)
+		}
+	}
+	d.paths[joinPath(path)] = line
+}
+
+// deep copy of a decoded value
+func copyTree(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		table := make(map[string]any, len(v))
+		for key, element := range v {
+			table[key] = copyTree(element)
+		}
+		return table
+	case []any:
+		array := make([]any, len(v))
+		for i, element := range v {
+			array[i] = copyTree(element)
+		}
+		return array
+	default:
+		return v
+	}
+}
+
+// check that brackets and quotes are closed
+func yamlBalanced(text string) bool {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote == '\'' && c == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth <= 0 && quote == 0
+}
+
+// split an &anchor off the front of a value
+func takeYAMLAnchor(text string) (string, string) {
+	if !strings.HasPrefix(text, "&") {
+		return text, ""
+	}
+	end := strings.IndexAny(text, " \t")
+	if end < 0 {
+		return "", text[1:]
+	}
+	return strings.TrimSpace(text[end:]), text[1:end]
+}
+
+// drop a !tag from the front of a value
+func skipYAMLTag(text string) string {
+	if !strings.HasPrefix(text, "!") {
+		return text
+	}
+	end := strings.IndexAny(text, " \t")
+	if end < 0 {
+		return ""
+	}
+	return strings.TrimSpace(text[end:])
+}
+
+// yamlFlow parses a single line value: flow collections, quoted strings,
+// aliases and plain scalars
+type yamlFlow struct {
+	decoder *yamlDecoder
+	src     string
+	pos     int
+	line    int
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.pos < len(f.src) && (f.src[f.pos] == ' ' || f.src[f.pos] == '\t') {
+		f.pos++
+	}
+}
+
+func (f *yamlFlow) peek() byte {
+	if f.pos >= len(f.src) {
+		return 0
+	}
+	return f.src[f.pos]
+}
+
+// parse a value, inFlow stops plain scalars at flow indicators
+func (f *yamlFlow) parseValue(path []string, inFlow bool) (any, error) {
+	f.skipSpace()
+	var anchor string
+	if f.peek() == '&' {
+		rest, name := takeYAMLAnchor(f.src[f.pos:])
+		f.pos = len(f.src) - len(rest)
+		anchor = name
+		f.skipSpace()
+	}
+	if f.peek() == '!' {
+		rest := skipYAMLTag(f.src[f.pos:])
+		f.pos = len(f.src) - len(rest)
+	}
+
+	var value any
+	var err error
+	switch c := f.peek(); c {
+	case '[':
+		value, err = f.parseSequence(path)
+	case '{':
+		value, err = f.parseMapping(path)
+	case '"', '\'':
+		value, err = f.parseQuoted()
+	case '*':
+		f.pos++
+		start := f.pos
+		for f.pos < len(f.src) && !strings.ContainsRune(" \t,]}", rune(f.src[f.pos])) {
+			f.pos++
+		}
+		name := f.src[start:f.pos]
+		aliased, ok := f.decoder.anchors[name]
+		if !ok {
+			return nil, f.decoder.errorf(f.line, "unknown alias *%s", name)
+		}
+		// aliases get their own copy, defined on the line of the alias
+		value = copyTree(aliased)
+		f.decoder.setLines(value, path, f.line)
+	default:
+		value, err = resolveYAMLScalar(f.parsePlain(inFlow, false))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor != "" {
+		f.decoder.anchors[anchor] = value
+	}
+	return value, nil
+}
+
+// read a plain scalar, in flow context it ends at , ] } and for keys at :
+func (f *yamlFlow) parsePlain(inFlow, isKey bool) string {
+	start := f.pos
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		if inFlow && (c == ',' || c == ']' || c == '}') {
+			break
+		}
+		if isKey && c == ':' && (f.pos+1 == len(f.src) || strings.ContainsRune(" \t,]}", rune(f.src[f.pos+1]))) {
+			break
+		}
+		f.pos++
+	}
+	return strings.TrimSpace(f.src[start:f.pos])
+}
+
+func (f *yamlFlow) parseQuoted() (string, error) {
+	quote := f.src[f.pos]
+	start := f.pos
+	f.pos++
+	for f.pos < len(f.src) {
+		c := f.src[f.pos]
+		if quote == '"' && c == '\\' {
+			f.pos += 2
+			continue
+		}
+		if c == quote {
+			if quote == '\'' && f.pos+1 < len(f.src) && f.src[f.pos+1] == '\'' {
+				f.pos += 2
+				continue
+			}
+			f.pos++
+			value, err := unquoteYAML(f.src[start:f.pos])
+			if err != nil {
+				return "", f.decoder.errorf(f.line, "%v", err)
+			}
+			return value, nil
+		}
+		f.pos++
+	}
+	return "", f.decoder.errorf(f.line, "unterminated string")
+}
+
+func (f *yamlFlow) parseSequence(path []string) ([]any, error) {
+	f.pos++
+	array := []any{}
+	for {
+		f.skipSpace()
+		switch f.peek() {
+		case 0:
+			return nil, f.decoder.errorf(f.line, "unterminated flow sequence")
+		case ']':
+			f.pos++
+			return array, nil
+		}
+
+		itemPath := append(append([]string{}, path...), strconv.Itoa(len(array)))
+		value, err := f.parseValue(itemPath, true)
+		if err != nil {
+			return nil, err
+		}
+		f.decoder.paths[joinPath(itemPath)] = f.line
+		array = append(array, value)
+
+		f.skipSpace()
+		switch f.peek() {
+		case ',':
+			f.pos++
+		case ']':
+		default:
+			return nil, f.decoder.errorf(f.line, "expected , or ] in flow sequence")
+		}
+	}
+}
+
+func (f *yamlFlow) parseMapping(path []string) (map[string]any, error) {
+	f.pos++
+	table := map[string]any{}
+	for {
+		f.skipSpace()
+		switch f.peek() {
+		case 0:
+			return nil, f.decoder.errorf(f.line, "unterminated flow mapping")
+		case '}':
+			f.pos++
+			return table, nil
+		}
+
+		var key string
+		if c := f.peek(); c == '"' || c == '\'' {
+			quoted, err := f.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			key = quoted
+		} else {
+			key = f.parsePlain(true, true)
+		}
+
+		f.skipSpace()
+		var value any
+		keyPath := append(append([]string{}, path...), key)
+		if f.peek() == ':' {
+			f.pos++
+			f.skipSpace()
+			if c := f.peek(); c != ',' && c != '}' {
+				parsed, err := f.parseValue(keyPath, true)
+				if err != nil {
+					return nil, err
+				}
+				value = parsed
+			}
+		}
+		table[key] = value
+		f.decoder.paths[joinPath(keyPath)] = f.line
+
+		f.skipSpace()
+		switch f.peek() {
+		case ',':
+			f.pos++
+		case '}':
+		default:
+			return nil, f.decoder.errorf(f.line, "expected , or } in flow mapping")
+		}
+	}
+}
+
+// unquote a single or double quoted yaml string, other text is returned as is
+func unquoteYAML(text string) (string, error) {
+	if len(text) < 2 {
+		return text, nil
+	}
+	if text[0] == '\'' && text[len(text)-1] == '\'' {
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	}
+	if text[0] != '"' || text[len(text)-1] != '"' {
+		return text, nil
+	}
+
+	body := text[1 : len(text)-1]
+	var builder strings.Builder
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if c != '\\' {
+			builder.WriteByte(c)
+			continue
+		}
+		i++
+		if i >= len(body) {
+			return "", fmt.Errorf("unterminated escape in %s", text)
+		}
+		switch body[i] {
+		case '0':
+			builder.WriteByte(0)
+		case 'a':
+			builder.WriteByte('\a')
+		case 'b':
+			builder.WriteByte('\b')
+		case 't', '\t':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'v':
+			builder.WriteByte('\v')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'e':
+			builder.WriteByte(0x1b)
+		case ' ', '"', '/', '\\':
+			builder.WriteByte(body[i])
+		case 'N':
+			builder.WriteRune('\u0085')
+		case '_':
+			builder.WriteRune('\u00a0')
+		case 'L':
+			builder.WriteRune('\u2028')
+		case 'P':
+			builder.WriteRune('\u2029')
+		case 'x', 'u', 'U':
+			size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[body[i]]
+			if i+1+size > len(body) {
+				return "", fmt.Errorf("short escape in %s", text)
+			}
+			code, err := strconv.ParseUint(body[i+1:i+1+size], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", fmt.Errorf("invalid escape in %s", text)
+			}
+			builder.WriteRune(rune(code))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape \\%c in %s", body[i], text)
+		}
+	}
+	return builder.String(), nil
+}
+
+// resolve a plain scalar to null, bool, int, float or string following the
+// yaml 1.2 core schema. Hex numbers stay strings, they are colors in
+// alacritty configs.
+func resolveYAMLScalar(text string) (any, error) {
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return nil, nil
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1), nil
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1), nil
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN(), nil
+	}
+
+	if strings.HasPrefix(text, "0o") {
+		if value, err := strconv.ParseInt(text[2:], 8, 64); err == nil {
+			return value, nil
+		}
+	}
+	if value, err := strconv.ParseInt(text, 10, 64); err == nil && isYAMLNumber(text) {
+		return value, nil
+	}
+	if value, err := strconv.ParseFloat(text, 64); err == nil && isYAMLNumber(text) {
+		return value, nil
+	}
+	return text, nil
+}
+
+// only plain decimal notation counts as a number
+func isYAMLNumber(text string) bool {
+	for i, c := range text {
+		switch {
+		case c >= '0' && c <= '9', c == '.':
+		case (c == '-' || c == '+') && (i == 0 || text[i-1] == 'e' || text[i-1] == 'E'):
+		case c == 'e' || c == 'E':
+		default:
+			return false
+		}
+	}
+	return true
+}
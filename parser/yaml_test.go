@@ -0,0 +1,94 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeYAML(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		want map[string]any
+	}{
+		{
+			"nested mapping",
+			"font:\n  normal:\n    family: Hack\n  size: 12.5\n",
+			map[string]any{"font": map[string]any{"normal": map[string]any{"family": "Hack"}, "size": 12.5}},
+		},
+		{
+			"anchor and alias",
+			"schemes:\n  dark: &dark\n    background: '#000000'\ncolors: *dark\n",
+			map[string]any{
+				"schemes": map[string]any{"dark": map[string]any{"background": "#000000"}},
+				"colors":  map[string]any{"background": "#000000"},
+			},
+		},
+		{
+			"merge key",
+			"base: &base\n  x: 1\n  y: 2\npadding:\n  <<: *base\n  y: 4\n",
+			map[string]any{
+				"base":    map[string]any{"x": int64(1), "y": int64(2)},
+				"padding": map[string]any{"x": int64(1), "y": int64(4)},
+			},
+		},
+		{
+			"literal block scalar",
+			"text: |\n  first\n  second\nafter: 1\n",
+			map[string]any{"text": "first\nsecond\n", "after": int64(1)},
+		},
+		{
+			"folded block scalar",
+			"text: >-\n  first\n  second\n",
+			map[string]any{"text": "first second"},
+		},
+		{
+			"nulls",
+			"a:\nb: ~\nc: null\nd: 'null'\n",
+			map[string]any{"a": nil, "b": nil, "c": nil, "d": "null"},
+		},
+		{
+			"sequence of mappings",
+			"key_bindings:\n  - { key: N, action: SpawnNewInstance }\n  - key: Q\n    mods: Control\n",
+			map[string]any{"key_bindings": []any{
+				map[string]any{"key": "N", "action": "SpawnNewInstance"},
+				map[string]any{"key": "Q", "mods": "Control"},
+			}},
+		},
+		{
+			"comments and quotes",
+			"shell: \"/bin/zsh\" # login shell\nchars: 'it''s'\n",
+			map[string]any{"shell": "/bin/zsh", "chars": "it's"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tree, err := decodeYAML("test.yml", []byte(test.yaml))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(tree.Root, test.want) {
+				t.Errorf("decodeYAML() = %#v, want %#v", tree.Root, test.want)
+			}
+		})
+	}
+}
+
+func TestDecodeYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+	}{
+		{"tab indentation", "font:\n\tsize: 12\n"},
+		{"unknown alias", "colors: *missing\n"},
+		{"unterminated flow", "a: [1, 2\n"},
+		{"top level sequence", "- a\n- b\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := decodeYAML("test.yml", []byte(test.yaml)); err == nil {
+				t.Errorf("decodeYAML(%q) succeeded, want an error", test.yaml)
+			}
+		})
+	}
+}